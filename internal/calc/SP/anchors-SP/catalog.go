@@ -0,0 +1,243 @@
+package anchors
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+type ProductKind string
+
+const (
+	ProductChemical   ProductKind = "chemical"
+	ProductMechanical ProductKind = "mechanical"
+)
+
+// PullOut holds characteristic pull-out resistances of one anchor (kN).
+type PullOut struct {
+	Cracked   float64 `json:"cracked"`
+	Uncracked float64 `json:"uncracked"`
+}
+
+// Variant is one diameter and embedment of a product with the approval
+// values. Resistances are characteristic, pull-out is keyed by concrete
+// class ("C20/25", "B25").
+type Variant struct {
+	DiameterMM     float64            `json:"diameter_mm"`
+	EmbedmentMM    float64            `json:"embedment_mm"`
+	SteelTensionKN float64            `json:"steel_tension_kn"`
+	SteelShearKN   float64            `json:"steel_shear_kn"`
+	GammaMs        float64            `json:"gamma_ms"`
+	GammaMsV       float64            `json:"gamma_ms_v"`
+	KCracked       float64            `json:"k_cracked"`
+	KUncracked     float64            `json:"k_uncracked"`
+	EdgeCrMM       float64            `json:"edge_cr_mm"`
+	EdgeMinMM      float64            `json:"edge_min_mm"`
+	SpacingMinMM   float64            `json:"spacing_min_mm"`
+	MemberMinMM    float64            `json:"member_min_mm"`
+	PullOutKN      map[string]PullOut `json:"pull_out_kn"`
+}
+
+// Product is one anchor line. Example products carry illustrative values
+// that are not taken from any approval; the built-in ones are all examples.
+type Product struct {
+	ID           string      `json:"id"`
+	Manufacturer string      `json:"manufacturer"`
+	Name         string      `json:"name"`
+	Kind         ProductKind `json:"kind"`
+	Approval     string      `json:"approval"`
+	Example      bool        `json:"example,omitempty"`
+	Variants     []Variant   `json:"variants"`
+}
+
+//go:embed catalog.json
+var builtinCatalog []byte
+
+// CatalogDir is scanned for *.json files in the catalog format. Products
+// found there are added to the built-in ones or replace them by id, so
+// admins can publish new approvals without a rebuild.
+var CatalogDir = "admin/anchors"
+
+// catalogCache keeps the merged catalog until a file in CatalogDir is
+// added, removed or modified.
+var catalogCache struct {
+	sync.Mutex
+	key      string
+	products []Product
+}
+
+// parseCatalog decodes and validates one catalog file.
+func parseCatalog(data []byte, src string) ([]Product, error) {
+	var f struct {
+		Products []Product `json:"products"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("anchor catalog %s: %w", src, err)
+	}
+	for _, p := range f.Products {
+		if p.ID == "" || len(p.Variants) == 0 {
+			return nil, fmt.Errorf("anchor catalog %s: product without id or variants", src)
+		}
+		if p.Kind != ProductChemical && p.Kind != ProductMechanical {
+			return nil, fmt.Errorf("anchor catalog %s: invalid kind of %s", src, p.ID)
+		}
+	}
+	return f.Products, nil
+}
+
+// LoadCatalog returns the built-in products merged with the ones in
+// CatalogDir, sorted by id. A file in CatalogDir that does not parse is
+// logged and skipped. The result is shared and must not be modified.
+func LoadCatalog() ([]Product, error) {
+	files, _ := filepath.Glob(filepath.Join(CatalogDir, "*.json"))
+	sort.Strings(files)
+	key := CatalogDir
+	for _, name := range files {
+		if fi, err := os.Stat(name); err == nil {
+			key += fmt.Sprintf("|%s %d %d", name, fi.ModTime().UnixNano(), fi.Size())
+		}
+	}
+	catalogCache.Lock()
+	defer catalogCache.Unlock()
+	if catalogCache.products != nil && catalogCache.key == key {
+		return catalogCache.products, nil
+	}
+
+	byID := map[string]Product{}
+	builtin, err := parseCatalog(builtinCatalog, "built-in")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range builtin {
+		byID[p.ID] = p
+	}
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err == nil {
+			var ps []Product
+			if ps, err = parseCatalog(data, filepath.Base(name)); err == nil {
+				for _, p := range ps {
+					byID[p.ID] = p
+				}
+				continue
+			}
+		}
+		log.Printf("anchor catalog skipped: %v", err)
+	}
+	out := make([]Product, 0, len(byID))
+	for _, p := range byID {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	catalogCache.key, catalogCache.products = key, out
+	return out, nil
+}
+
+// findVariant looks up a product variant by diameter and, when given, by
+// embedment depth. Without an embedment the first listed one is used. The
+// variant is a copy with the default γMs filled in.
+func findVariant(id string, d, hef float64) (Product, *Variant, error) {
+	products, err := LoadCatalog()
+	if err != nil {
+		return Product{}, nil, err
+	}
+	for _, p := range products {
+		if p.ID != id {
+			continue
+		}
+		for _, v := range p.Variants {
+			if math.Abs(v.DiameterMM-d) > 0.01 {
+				continue
+			}
+			if hef > 0 && math.Abs(v.EmbedmentMM-hef) > 0.5 {
+				continue
+			}
+			if v.GammaMs <= 0 {
+				v.GammaMs = 1.5
+			}
+			if v.GammaMsV <= 0 {
+				v.GammaMsV = 1.25
+			}
+			return p, &v, nil
+		}
+		return Product{}, nil, fmt.Errorf("product %s has no variant d=%g hef=%g", id, d, hef)
+	}
+	return Product{}, nil, fmt.Errorf("unknown anchor product %s", id)
+}
+
+// productNote names the product in the result notes and flags example data.
+func productNote(p Product) string {
+	if p.Example {
+		return "Example product " + p.ID + ": illustrative values, not from an approval; do not use for design."
+	}
+	if p.Approval == "" {
+		return "Product " + p.ID + ": approval values."
+	}
+	return "Product " + p.ID + ": approval values of " + p.Approval + "."
+}
+
+// pullOut returns the characteristic pull-out resistance for the listed
+// class nearest below fck, or 0 when the product gives no values.
+func (v *Variant) pullOut(fck float64, cracked bool) (float64, error) {
+	if len(v.PullOutKN) == 0 {
+		return 0, nil
+	}
+	best, found := 0.0, false
+	var po PullOut
+	for class, p := range v.PullOutKN {
+		f, err := ParseConcreteClass(class)
+		if err != nil {
+			return 0, err
+		}
+		if f <= fck+1e-9 && (!found || f > best) {
+			best, po, found = f, p, true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("concrete class is below the product approval range")
+	}
+	if cracked {
+		return po.Cracked, nil
+	}
+	return po.Uncracked, nil
+}
+
+// steelResistance returns the design tension and shear resistance of one
+// anchor (kN), from the approval when a product variant is given.
+func steelResistance(d, fy, gammaM float64, v *Variant) (float64, float64) {
+	if v != nil {
+		return v.SteelTensionKN / v.GammaMs, v.SteelShearKN / v.GammaMsV
+	}
+	area := math.Pi * d * d / 4.0
+	return area * fy / gammaM / 1000.0, 0.6 * area * fy / gammaM / 1000.0
+}
+
+// productLimits reports the minimum edge, spacing and member thickness of
+// the approval that the layout violates.
+func productLimits(v *Variant, g layout, h float64) string {
+	msg := ""
+	if v.EdgeMinMM > 0 && minEdge(g) < v.EdgeMinMM {
+		msg += fmt.Sprintf(" Edge distance below cmin=%g mm.", v.EdgeMinMM)
+	}
+	if v.SpacingMinMM > 0 {
+		smin := math.Inf(1)
+		for i := range g.pts {
+			for j := i + 1; j < len(g.pts); j++ {
+				smin = math.Min(smin, math.Hypot(g.pts[i][0]-g.pts[j][0], g.pts[i][1]-g.pts[j][1]))
+			}
+		}
+		if smin < v.SpacingMinMM {
+			msg += fmt.Sprintf(" Spacing below smin=%g mm.", v.SpacingMinMM)
+		}
+	}
+	if v.MemberMinMM > 0 && h > 0 && h < v.MemberMinMM {
+		msg += fmt.Sprintf(" Member thickness below hmin=%g mm.", v.MemberMinMM)
+	}
+	return msg
+}
@@ -0,0 +1,261 @@
+package anchors
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type AnchorType string
+
+const (
+	AnchorCastIn        AnchorType = "cast-in"
+	AnchorPostInstalled AnchorType = "post-installed"
+)
+
+// layout is the anchor group in plan with the member edges (mm). Missing
+// edges are at ±Inf.
+type layout struct {
+	pts                    [][2]float64
+	xMin, xMax, yMin, yMax float64
+}
+
+type concreteInput struct {
+	anchorType AnchorType
+	d, dh      float64
+	hef        float64
+	h          float64
+	fck        float64
+	cracked    bool
+	gammaMc    float64
+
+	// Product approval values; zero means the CCD defaults
+	k1      float64
+	ccrN    float64
+	pullOut float64
+}
+
+// ParseConcreteClass converts a class such as "B25" or "C20/25" to the
+// characteristic cylinder strength fck (MPa).
+func ParseConcreteClass(class string) (float64, error) {
+	c := strings.ToUpper(strings.TrimSpace(class))
+	c = strings.Replace(c, "В", "B", 1)
+	switch {
+	case strings.HasPrefix(c, "B"):
+		b, err := strconv.ParseFloat(c[1:], 64)
+		if err != nil || b <= 0 {
+			return 0, fmt.Errorf("invalid concrete class")
+		}
+		return 0.8 * b, nil
+	case strings.HasPrefix(c, "C"):
+		parts := strings.SplitN(c[1:], "/", 2)
+		f, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil || f <= 0 {
+			return 0, fmt.Errorf("invalid concrete class")
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("invalid concrete class")
+}
+
+// concreteTension returns cone, pull-out and splitting resistances of the
+// group for a tension resultant with eccentricities ex, ey from the centroid
+// of the tensioned anchors.
+func concreteTension(ci concreteInput, g layout, ex, ey float64) (cone, pullOut, splitting float64) {
+	n := float64(len(g.pts))
+	k1 := 11.0
+	if ci.anchorType == AnchorCastIn {
+		k1 = 12.7
+	}
+	if ci.cracked {
+		k1 = 7.7
+		if ci.anchorType == AnchorCastIn {
+			k1 = 8.9
+		}
+	}
+	if ci.k1 > 0 {
+		k1 = ci.k1
+	}
+	n0 := k1 * math.Sqrt(ci.fck) * math.Pow(ci.hef, 1.5) / 1000.0
+	ccr := 1.5 * ci.hef
+	if ci.ccrN > 0 {
+		ccr = ci.ccrN
+	}
+	cmin := minEdge(g)
+	psiRe := math.Min(0.5+ci.hef/200, 1)
+	psiEc := 1 / (1 + 2*ex/(2*ccr)) / (1 + 2*ey/(2*ccr))
+
+	area := projectedArea(g, ccr)
+	cone = n0 * area / (4 * ccr * ccr) * math.Min(0.7+0.3*cmin/ccr, 1) * psiRe * psiEc / ci.gammaMc
+
+	if ci.pullOut > 0 {
+		pullOut = n * ci.pullOut
+	} else if ci.dh > ci.d {
+		k2 := 10.5
+		if ci.cracked {
+			k2 = 7.5
+		}
+		ah := math.Pi / 4 * (ci.dh*ci.dh - ci.d*ci.d)
+		pullOut = n * k2 * ah * ci.fck / 1000.0 / ci.gammaMc
+	}
+
+	// Splitting is checked for uncracked concrete with a known member depth
+	if !ci.cracked && ci.h > 0 {
+		ccrSp := 2 * ci.hef
+		hmin := 2 * ci.hef
+		psiH := math.Pow(ci.h/hmin, 2.0/3.0)
+		// EN 1992-4 (7.23), at most 2
+		psiH = math.Min(math.Min(psiH, math.Max(1, math.Pow((ci.hef+1.5*cmin)/hmin, 2.0/3.0))), 2)
+		areaSp := projectedArea(g, ccrSp)
+		splitting = n0 * areaSp / (4 * ccrSp * ccrSp) * math.Min(0.7+0.3*cmin/ccrSp, 1) * psiRe * psiEc * psiH / ci.gammaMc
+	}
+	return cone, pullOut, splitting
+}
+
+// concreteEdge returns the concrete edge resistance of the group for shear
+// acting towards the edge in direction (dx, dy), one of the four axis
+// directions. Returns 0 when there is no edge in that direction.
+func concreteEdge(ci concreteInput, g layout, dx, dy float64) float64 {
+	// Rotate the layout so the loaded edge is at +y
+	r := g
+	switch {
+	case dx > 0:
+		r = rotate(g, func(p [2]float64) [2]float64 { return [2]float64{-p[1], p[0]} })
+	case dx < 0:
+		r = rotate(g, func(p [2]float64) [2]float64 { return [2]float64{p[1], -p[0]} })
+	case dy < 0:
+		r = rotate(g, func(p [2]float64) [2]float64 { return [2]float64{-p[0], -p[1]} })
+	}
+	if math.IsInf(r.yMax, 1) {
+		return 0
+	}
+	front := math.Inf(-1)
+	for _, p := range r.pts {
+		front = math.Max(front, p[1])
+	}
+	c1 := r.yMax - front
+	if c1 <= 0 {
+		return 0
+	}
+	k1 := 2.4
+	if ci.cracked {
+		k1 = 1.7
+	}
+	lf := math.Min(ci.hef, 12*ci.d)
+	alpha := 0.1 * math.Sqrt(lf/c1)
+	beta := 0.1 * math.Pow(ci.d/c1, 0.2)
+	v0 := k1 * math.Pow(ci.d, alpha) * math.Pow(lf, beta) * math.Sqrt(ci.fck) * math.Pow(c1, 1.5) / 1000.0
+
+	// Failure surface width along the edge from the front row anchors
+	var spans [][2]float64
+	for _, p := range r.pts {
+		if front-p[1] < 1e-6 {
+			spans = append(spans, [2]float64{
+				math.Max(p[0]-1.5*c1, r.xMin),
+				math.Min(p[0]+1.5*c1, r.xMax),
+			})
+		}
+	}
+	width := unionLength(spans)
+	height := 1.5 * c1
+	psiH := 1.0
+	if ci.h > 0 && ci.h < height {
+		psiH = math.Sqrt(1.5 * c1 / ci.h)
+		height = ci.h
+	}
+	c2 := math.Inf(1)
+	for _, p := range r.pts {
+		c2 = math.Min(c2, math.Min(p[0]-r.xMin, r.xMax-p[0]))
+	}
+	psiS := math.Min(0.7+0.3*c2/(1.5*c1), 1)
+	return v0 * width * height / (4.5 * c1 * c1) * psiS * psiH / ci.gammaMc
+}
+
+// pryOut is the pry-out resistance from the cone resistance.
+func pryOut(hef, cone float64) float64 {
+	if hef < 60 {
+		return cone
+	}
+	return 2 * cone
+}
+
+func rotate(g layout, f func([2]float64) [2]float64) layout {
+	r := layout{}
+	for _, p := range g.pts {
+		r.pts = append(r.pts, f(p))
+	}
+	corners := [][2]float64{{g.xMin, g.yMin}, {g.xMax, g.yMax}}
+	a := f(corners[0])
+	b := f(corners[1])
+	r.xMin, r.xMax = math.Min(a[0], b[0]), math.Max(a[0], b[0])
+	r.yMin, r.yMax = math.Min(a[1], b[1]), math.Max(a[1], b[1])
+	return r
+}
+
+func minEdge(g layout) float64 {
+	c := math.Inf(1)
+	for _, p := range g.pts {
+		c = math.Min(c, math.Min(p[0]-g.xMin, g.xMax-p[0]))
+		c = math.Min(c, math.Min(p[1]-g.yMin, g.yMax-p[1]))
+	}
+	return c
+}
+
+// projectedArea returns the area of the union of squares of half-size ccr
+// around the anchors, clipped by the member edges.
+func projectedArea(g layout, ccr float64) float64 {
+	type rect struct{ x0, x1, y0, y1 float64 }
+	var rs []rect
+	var xs []float64
+	for _, p := range g.pts {
+		r := rect{
+			x0: math.Max(p[0]-ccr, g.xMin),
+			x1: math.Min(p[0]+ccr, g.xMax),
+			y0: math.Max(p[1]-ccr, g.yMin),
+			y1: math.Min(p[1]+ccr, g.yMax),
+		}
+		rs = append(rs, r)
+		xs = append(xs, r.x0, r.x1)
+	}
+	sort.Float64s(xs)
+	area := 0.0
+	for i := 0; i+1 < len(xs); i++ {
+		x0, x1 := xs[i], xs[i+1]
+		if x1-x0 <= 0 {
+			continue
+		}
+		var spans [][2]float64
+		for _, r := range rs {
+			if r.x0 <= x0 && r.x1 >= x1 {
+				spans = append(spans, [2]float64{r.y0, r.y1})
+			}
+		}
+		area += (x1 - x0) * unionLength(spans)
+	}
+	return area
+}
+
+func unionLength(spans [][2]float64) float64 {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	total := 0.0
+	cur0, cur1 := math.Inf(-1), math.Inf(-1)
+	for _, s := range spans {
+		if s[1] <= s[0] {
+			continue
+		}
+		if s[0] > cur1 {
+			if cur1 > cur0 {
+				total += cur1 - cur0
+			}
+			cur0, cur1 = s[0], s[1]
+			continue
+		}
+		cur1 = math.Max(cur1, s[1])
+	}
+	if cur1 > cur0 {
+		total += cur1 - cur0
+	}
+	return total
+}
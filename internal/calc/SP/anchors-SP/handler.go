@@ -21,3 +21,28 @@ func (h *Handler) Calc(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
+
+func (h *Handler) Plate(w http.ResponseWriter, r *http.Request) {
+	var input PlateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	res, err := CalculatePlate(input)
+	if err != nil {
+		http.Error(w, "Calculation error", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *Handler) Catalog(w http.ResponseWriter, r *http.Request) {
+	products, err := LoadCatalog()
+	if err != nil {
+		http.Error(w, "Catalog error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
@@ -5,38 +5,66 @@ import (
 )
 
 type Input struct {
-	WeldSizeMM float64 `json:"weld_size_mm"`
+	WeldSizeMM   float64 `json:"weld_size_mm"`
 	WeldLengthMM float64 `json:"weld_length_mm"`
-	FvwMPa     float64 `json:"fvw_mpa"`
-	GammaM     float64 `json:"gamma_m"`
-	ShearKN    float64 `json:"shear_kn"`
+	Electrode    string  `json:"electrode"`
+	Process      Process `json:"process"`
+	RunMPa       float64 `json:"run_mpa"`
+	GammaWf      float64 `json:"gamma_wf"`
+	GammaWz      float64 `json:"gamma_wz"`
+	GammaC       float64 `json:"gamma_c"`
+	ShearKN      float64 `json:"shear_kn"`
 }
 
 type Result struct {
-	CapacityKN  float64 `json:"capacity_kn"`
-	Utilization float64 `json:"utilization"`
-	OK          bool    `json:"ok"`
-	Notes       string  `json:"notes"`
+	BetaF            float64 `json:"beta_f"`
+	BetaZ            float64 `json:"beta_z"`
+	RwfMPa           float64 `json:"rwf_mpa"`
+	RwzMPa           float64 `json:"rwz_mpa"`
+	MetalCapacityKN  float64 `json:"metal_capacity_kn"`
+	FusionCapacityKN float64 `json:"fusion_capacity_kn"`
+	CapacityKN       float64 `json:"capacity_kn"`
+	Governing        string  `json:"governing"`
+	Utilization      float64 `json:"utilization"`
+	OK               bool    `json:"ok"`
+	Notes            string  `json:"notes"`
 }
 
 func Calculate(in Input) (Result, error) {
 	if in.WeldSizeMM <= 0 || in.WeldLengthMM <= 0 || in.ShearKN <= 0 {
 		return Result{}, fmt.Errorf("invalid input")
 	}
-	if in.FvwMPa <= 0 {
-		in.FvwMPa = 180
+	wd, err := newSP16Weld(in.Electrode, in.RunMPa, in.GammaWf, in.GammaWz, in.GammaC)
+	if err != nil {
+		return Result{}, err
 	}
-	if in.GammaM <= 0 {
-		in.GammaM = 1.25
+	bf, bz, err := weldBeta(in.Process, in.WeldSizeMM)
+	if err != nil {
+		return Result{}, err
+	}
+	// Design length is 10 mm shorter than the full weld length
+	lw := in.WeldLengthMM - 10
+	if lw <= 0 {
+		return Result{}, fmt.Errorf("weld too short")
+	}
+	metal := wd.metal(bf) * in.WeldSizeMM * lw / 1000.0
+	fusion := wd.fusion(bz) * in.WeldSizeMM * lw / 1000.0
+	capacity, governing := metal, "metal"
+	if fusion < metal {
+		capacity, governing = fusion, "fusion"
 	}
-	// Throat thickness a = 0.7 * s
-	a := 0.7 * in.WeldSizeMM
-	capacity := (a * in.WeldLengthMM * in.FvwMPa / in.GammaM) / 1000.0
 	util := in.ShearKN / capacity
 	return Result{
-		CapacityKN:  capacity,
-		Utilization: util,
-		OK:          util <= 1.0,
-		Notes:       "Placeholder. Weld design is not covered by SP63.",
+		BetaF:            bf,
+		BetaZ:            bz,
+		RwfMPa:           wd.rwf,
+		RwzMPa:           wd.rwz,
+		MetalCapacityKN:  metal,
+		FusionCapacityKN: fusion,
+		CapacityKN:       capacity,
+		Governing:        governing,
+		Utilization:      util,
+		OK:               util <= 1.0,
+		Notes:            "Fillet weld check per SP16 on weld metal (βf, Rwf) and fusion boundary (βz, Rwz) sections.",
 	}, nil
 }
@@ -0,0 +1,96 @@
+package joints
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Process string
+
+const (
+	ProcessManual     Process = "manual"
+	ProcessMechanized Process = "mechanized"
+	ProcessAutomatic  Process = "automatic"
+)
+
+// Rwf by electrode type, SP16 table Г.2 (MPa).
+var electrodeRwf = map[string]float64{
+	"E42":  180,
+	"E42A": 180,
+	"E46":  200,
+	"E46A": 200,
+	"E50":  215,
+	"E50A": 215,
+	"E60":  240,
+	"E70":  280,
+	"E85":  340,
+}
+
+type sp16Weld struct {
+	rwf, rwz float64
+	gwf, gwz float64
+	gc       float64
+}
+
+func newSP16Weld(electrode string, runMPa, gwf, gwz, gc float64) (sp16Weld, error) {
+	e := strings.ToUpper(strings.TrimSpace(electrode))
+	e = strings.Replace(e, "Э", "E", 1)
+	e = strings.Replace(e, "А", "A", 1)
+	if e == "" {
+		e = "E42"
+	}
+	rwf, ok := electrodeRwf[e]
+	if !ok {
+		return sp16Weld{}, fmt.Errorf("unknown electrode %q", electrode)
+	}
+	if runMPa <= 0 {
+		runMPa = 370
+	}
+	if gwf <= 0 {
+		gwf = 1.0
+	}
+	if gwz <= 0 {
+		gwz = 1.0
+	}
+	if gc <= 0 {
+		gc = 1.0
+	}
+	return sp16Weld{rwf: rwf, rwz: 0.45 * runMPa, gwf: gwf, gwz: gwz, gc: gc}, nil
+}
+
+// weldBeta returns βf and βz for a fillet weld per SP16 table 39.
+func weldBeta(p Process, kf float64) (bf, bz float64, err error) {
+	switch p {
+	case "", ProcessManual:
+		return 0.7, 1.0, nil
+	case ProcessMechanized:
+		switch {
+		case kf <= 8:
+			return 0.9, 1.05, nil
+		case kf <= 12:
+			return 0.8, 1.05, nil
+		default:
+			return 0.7, 1.0, nil
+		}
+	case ProcessAutomatic:
+		switch {
+		case kf <= 8:
+			return 1.1, 1.15, nil
+		case kf <= 16:
+			return 0.9, 1.05, nil
+		default:
+			return 0.7, 1.0, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid welding process")
+}
+
+// metal and fusion return design resistance per mm of weld length per mm of
+// leg (N/mm2) for the weld metal and fusion boundary sections.
+func (w sp16Weld) metal(bf float64) float64 {
+	return bf * w.rwf * w.gwf * w.gc
+}
+
+func (w sp16Weld) fusion(bz float64) float64 {
+	return bz * w.rwz * w.gwz * w.gc
+}
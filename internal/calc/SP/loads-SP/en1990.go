@@ -0,0 +1,179 @@
+package loads
+
+import "fmt"
+
+type psiSet struct{ psi0, psi1, psi2 float64 }
+
+// ψ factors for buildings, EN 1990 table A1.1. Snow is for sites below
+// 1000 m; "snow-high" above.
+var enPsi = map[string]psiSet{
+	"A":           {0.7, 0.5, 0.3},
+	"B":           {0.7, 0.5, 0.3},
+	"C":           {0.7, 0.7, 0.6},
+	"D":           {0.7, 0.7, 0.6},
+	"E":           {1.0, 0.9, 0.8},
+	"F":           {0.7, 0.7, 0.6},
+	"G":           {0.7, 0.5, 0.3},
+	"H":           {0, 0, 0},
+	"snow":        {0.5, 0.2, 0},
+	"snow-high":   {0.7, 0.5, 0.2},
+	"wind":        {0.6, 0.2, 0},
+	"temperature": {0.6, 0.5, 0},
+	"crane":       {1.0, 0.9, 0},
+}
+
+var defaultCategory = map[CaseType]string{
+	CaseLiveLong:    "B",
+	CaseLiveShort:   "B",
+	CaseSnow:        "snow",
+	CaseWind:        "wind",
+	CaseTemperature: "temperature",
+	CaseCrane:       "crane",
+}
+
+// Partial factors of EN 1990 table A1.2(B) with the recommended values.
+const (
+	enGammaGSup = 1.35
+	enGammaGInf = 1.0
+	enGammaQ    = 1.5
+	enXi        = 0.85
+)
+
+const (
+	Kind610a           = "6.10a"
+	Kind610b           = "6.10b"
+	KindAccidental     = "accidental"
+	KindCharacteristic = "characteristic"
+	KindFrequent       = "frequent"
+	KindQuasiPermanent = "quasi-permanent"
+)
+
+// CombineEN1990 generates the STR/GEO set B combinations 6.10a and 6.10b
+// with every variable action leading in turn, the accidental combination
+// 6.11b, and the characteristic, frequent and quasi-permanent SLS
+// combinations. Permanent actions that act favourably take γG,inf.
+func CombineEN1990(cases []LoadCase) ([]Combination, error) {
+	perm, variable, special, err := splitCases(cases)
+	if err != nil {
+		return nil, err
+	}
+	// Cases may share a name, so the factors follow the case index
+	psi := make([]psiSet, len(variable))
+	for i, c := range variable {
+		cat := c.Category
+		if cat == "" {
+			cat = defaultCategory[c.Type]
+		}
+		p, ok := enPsi[cat]
+		if !ok {
+			return nil, fmt.Errorf("invalid category %q of %s", cat, c.Name)
+		}
+		if c.Psi0 != nil {
+			p.psi0 = *c.Psi0
+		}
+		if c.Psi1 != nil {
+			p.psi1 = *c.Psi1
+		}
+		if c.Psi2 != nil {
+			p.psi2 = *c.Psi2
+		}
+		psi[i] = p
+	}
+
+	var out []Combination
+	count := map[string]int{}
+	seen := map[string]bool{}
+	emit := func(kind string, terms []term) {
+		// Same-named cases print alike, so the value joins the key
+		f := formula(terms)
+		key := fmt.Sprintf("%s %s %g", kind, f, sumTerms(terms))
+		if len(terms) == 0 || seen[key] {
+			return
+		}
+		seen[key] = true
+		count[kind]++
+		out = append(out, Combination{
+			Name:    fmt.Sprintf("%s %d", kind, count[kind]),
+			Kind:    kind,
+			ValueKN: sumTerms(terms),
+			Formula: f,
+		})
+	}
+	permAt := func(gamma, xi float64) []term {
+		ts := make([]term, 0, len(perm))
+		for _, c := range perm {
+			ts = append(ts, term{c: c, gamma: gamma, psi: xi})
+		}
+		return ts
+	}
+	// accompany returns the variable terms with the leading one (index
+	// lead, -1 for none) at factor f1 and the others at f.
+	accompany := func(sub []int, lead int, gamma float64, f1, f func(psiSet) float64) []term {
+		var ts []term
+		for i, j := range sub {
+			k := f(psi[j])
+			if i == lead {
+				k = f1(psi[j])
+			}
+			if k == 0 {
+				continue
+			}
+			ts = append(ts, term{c: variable[j], gamma: gamma, psi: k})
+		}
+		return ts
+	}
+	one := func(psiSet) float64 { return 1 }
+	psi0 := func(p psiSet) float64 { return p.psi0 }
+	psi1 := func(p psiSet) float64 { return p.psi1 }
+	psi2 := func(p psiSet) float64 { return p.psi2 }
+	join := func(a, b []term) []term { return append(append([]term{}, a...), b...) }
+
+	for _, sub := range subsetIndices(variable) {
+		// 6.10a: all variable actions at ψ0
+		emit(Kind610a, join(permAt(enGammaGSup, 1), accompany(sub, -1, enGammaQ, psi0, psi0)))
+		if len(sub) == 0 {
+			emit(Kind610a, permAt(enGammaGInf, 1))
+		}
+		for lead := range sub {
+			vs := accompany(sub, lead, enGammaQ, one, psi0)
+			emit(Kind610b, join(permAt(enGammaGSup, enXi), vs))
+			emit(Kind610b, join(permAt(enGammaGInf, 1), vs))
+		}
+	}
+	for _, a := range special {
+		ad := term{c: a, gamma: 1, psi: 1}
+		for _, sub := range subsetIndices(variable) {
+			if len(sub) == 0 {
+				emit(KindAccidental, append(permAt(1, 1), ad))
+			}
+			for lead := range sub {
+				emit(KindAccidental, append(join(permAt(1, 1), accompany(sub, lead, 1, psi1, psi2)), ad))
+			}
+		}
+	}
+	for _, sub := range subsetIndices(variable) {
+		if len(sub) == 0 {
+			emit(KindCharacteristic, permAt(1, 1))
+		}
+		for lead := range sub {
+			emit(KindCharacteristic, join(permAt(1, 1), accompany(sub, lead, 1, one, psi0)))
+			emit(KindFrequent, join(permAt(1, 1), accompany(sub, lead, 1, psi1, psi2)))
+		}
+		emit(KindQuasiPermanent, join(permAt(1, 1), accompany(sub, -1, 1, psi2, psi2)))
+	}
+	return out, nil
+}
+
+// splitKinds separates ultimate from serviceability combinations.
+func splitKinds(combos []Combination) (uls []Combination, sls map[string][]Combination) {
+	sls = map[string][]Combination{}
+	for _, c := range combos {
+		switch c.Kind {
+		case KindCharacteristic, KindFrequent, KindQuasiPermanent:
+			sls[c.Kind] = append(sls[c.Kind], c)
+		default:
+			uls = append(uls, c)
+		}
+	}
+	return uls, sls
+}
@@ -0,0 +1,153 @@
+package loads
+
+import (
+	"fmt"
+	"math"
+)
+
+// Occupancy is an imposed floor load category. Group selects the area
+// reduction: 1 for ψA1/ψn1, 2 for ψA2/ψn2, 0 for none.
+type Occupancy struct {
+	Name     string  `json:"name"`
+	FullKPa  float64 `json:"full_kpa"`
+	LongKPa  float64 `json:"long_kpa"`
+	Group    int     `json:"group"`
+	Category string  `json:"en_category"`
+}
+
+// Occupancies holds the normative and reduced (long-term) floor loads of
+// SP20 table 8.3 (kPa), with the matching EN 1991-1-1 category.
+var Occupancies = map[string]Occupancy{
+	"residential": {Name: "Residential rooms", FullKPa: 1.5, LongKPa: 0.3, Group: 1, Category: "A"},
+	"office":      {Name: "Offices", FullKPa: 2.0, LongKPa: 0.7, Group: 1, Category: "B"},
+	"classroom":   {Name: "Classrooms", FullKPa: 2.0, LongKPa: 0.7, Group: 1, Category: "C"},
+	"retail":      {Name: "Retail floors", FullKPa: 4.0, LongKPa: 1.4, Group: 2, Category: "D"},
+	"assembly":    {Name: "Halls and lobbies", FullKPa: 4.0, LongKPa: 1.4, Group: 2, Category: "C"},
+	"parking":     {Name: "Parking for cars", FullKPa: 3.5, LongKPa: 1.0, Group: 0, Category: "F"},
+	"stairs":      {Name: "Stairs and corridors", FullKPa: 3.0, LongKPa: 1.0, Group: 0, Category: "A"},
+	"balcony":     {Name: "Balconies", FullKPa: 2.0, LongKPa: 0.7, Group: 0, Category: "A"},
+}
+
+// LiveLoad picks an occupancy for an element carrying AreaM2 of floor on
+// each of Storeys floors.
+type LiveLoad struct {
+	Occupancy string  `json:"occupancy"`
+	AreaM2    float64 `json:"area_m2"`
+	Storeys   int     `json:"storeys"`
+}
+
+type LiveResult struct {
+	Occupancy string  `json:"occupancy"`
+	FullKPa   float64 `json:"full_kpa"`
+	LongKPa   float64 `json:"long_kpa"`
+	PsiA      float64 `json:"psi_a"`
+	PsiN      float64 `json:"psi_n"`
+	FullKN    float64 `json:"full_kn"`
+	LongKN    float64 `json:"long_kn"`
+	GammaF    float64 `json:"gamma_f"`
+}
+
+// LiveCases converts the floor loads into load cases, reduced by ψA for one
+// floor or ψn for several: the reduced value as a long-term case and the
+// rest of the full value as a short-term one. γf is 1.3 below 2 kPa and
+// 1.2 otherwise, SP20 8.2.2.
+func LiveCases(live []LiveLoad) ([]LoadCase, []LiveResult, error) {
+	var cases []LoadCase
+	var out []LiveResult
+	for _, l := range live {
+		occ, ok := Occupancies[l.Occupancy]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown occupancy %q", l.Occupancy)
+		}
+		if l.AreaM2 <= 0 {
+			return nil, nil, fmt.Errorf("invalid loaded area")
+		}
+		n := l.Storeys
+		if n <= 0 {
+			n = 1
+		}
+		psiA := areaFactor(occ.Group, l.AreaM2)
+		psi := psiA
+		if n > 1 {
+			psi = storeyFactor(occ.Group, psiA, n)
+		}
+		gf := 1.2
+		if occ.FullKPa < 2.0 {
+			gf = 1.3
+		}
+		r := LiveResult{
+			Occupancy: l.Occupancy,
+			FullKPa:   occ.FullKPa,
+			LongKPa:   occ.LongKPa,
+			PsiA:      psiA,
+			PsiN:      psi,
+			FullKN:    psi * occ.FullKPa * l.AreaM2 * float64(n),
+			LongKN:    psi * occ.LongKPa * l.AreaM2 * float64(n),
+			GammaF:    gf,
+		}
+		out = append(out, r)
+		if r.LongKN > 0 {
+			cases = append(cases, LoadCase{
+				Name:     "Live " + l.Occupancy + " long",
+				Type:     CaseLiveLong,
+				ValueKN:  r.LongKN,
+				GammaF:   gf,
+				Category: occ.Category,
+			})
+		}
+		if r.FullKN > r.LongKN {
+			cases = append(cases, LoadCase{
+				Name:     "Live " + l.Occupancy + " short",
+				Type:     CaseLiveShort,
+				ValueKN:  r.FullKN - r.LongKN,
+				GammaF:   gf,
+				Category: occ.Category,
+			})
+		}
+	}
+	return cases, out, nil
+}
+
+// enLiveCases keeps each floor load as one action at its full value, since
+// EN 1990 does not split imposed loads by duration.
+func enLiveCases(live []LiveResult) []LoadCase {
+	cases := make([]LoadCase, 0, len(live))
+	for _, r := range live {
+		cases = append(cases, LoadCase{
+			Name:     "Live " + r.Occupancy,
+			Type:     CaseLiveShort,
+			ValueKN:  r.FullKN,
+			GammaF:   r.GammaF,
+			Category: Occupancies[r.Occupancy].Category,
+		})
+	}
+	return cases
+}
+
+// areaFactor is ψA1 = 0.4 + 0.6/√(A/9) or ψA2 = 0.5 + 0.5/√(A/36) for
+// areas above A1 = 9 m2 and A2 = 36 m2, SP20 (8.1), (8.2).
+func areaFactor(group int, area float64) float64 {
+	switch group {
+	case 1:
+		if area > 9 {
+			return 0.4 + 0.6/math.Sqrt(area/9)
+		}
+	case 2:
+		if area > 36 {
+			return 0.5 + 0.5/math.Sqrt(area/36)
+		}
+	}
+	return 1
+}
+
+// storeyFactor is ψn1 = 0.4 + (ψA1 - 0.4)/√n or ψn2 = 0.5 + (ψA2 - 0.5)/√n,
+// SP20 (8.3), (8.4).
+func storeyFactor(group int, psiA float64, n int) float64 {
+	switch group {
+	case 1:
+		return 0.4 + (psiA-0.4)/math.Sqrt(float64(n))
+	case 2:
+		return 0.5 + (psiA-0.5)/math.Sqrt(float64(n))
+	}
+	return 1
+}
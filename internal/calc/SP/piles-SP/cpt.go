@@ -0,0 +1,188 @@
+package piles
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// CPTPoint is a reading of the static cone penetration test: cone
+// resistance qc (MPa) and sleeve friction fs (kPa). SoilType is optional;
+// without it sands are told from clays by fs/qc below 2%.
+type CPTPoint struct {
+	DepthM   float64 `json:"depth_m"`
+	QcMPa    float64 `json:"qc_mpa"`
+	FsKPa    float64 `json:"fs_kpa"`
+	SoilType string  `json:"soil_type,omitempty"`
+}
+
+// β1 by the mean cone resistance near the toe (MPa), SP24 table 7.16.
+var (
+	beta1Qs = []float64{1, 2.5, 5, 7.5, 10, 15, 20, 30}
+	beta1   = []float64{0.90, 0.80, 0.65, 0.55, 0.45, 0.35, 0.30, 0.20}
+)
+
+// β2 by the sleeve friction (kPa) for a type II cone, SP24 table 7.16.
+var (
+	beta2Fs   = []float64{20, 40, 60, 80, 100, 120}
+	beta2Sand = []float64{2.40, 1.65, 1.20, 1.00, 0.85, 0.75}
+	beta2Clay = []float64{1.50, 1.00, 0.75, 0.60, 0.50, 0.40}
+)
+
+const (
+	cptStepM   = 0.05
+	sandRatioF = 0.02
+)
+
+// cptAt interpolates the sounding at depth z.
+func cptAt(points []CPTPoint, z float64) CPTPoint {
+	i := sort.Search(len(points), func(i int) bool { return points[i].DepthM >= z })
+	switch {
+	case i == 0:
+		return points[0]
+	case i == len(points):
+		return points[len(points)-1]
+	}
+	a, b := points[i-1], points[i]
+	t := (z - a.DepthM) / (b.DepthM - a.DepthM)
+	p := CPTPoint{
+		DepthM:   z,
+		QcMPa:    a.QcMPa + t*(b.QcMPa-a.QcMPa),
+		FsKPa:    a.FsKPa + t*(b.FsKPa-a.FsKPa),
+		SoilType: a.SoilType,
+	}
+	if t > 0.5 {
+		p.SoilType = b.SoilType
+	}
+	return p
+}
+
+func (p CPTPoint) sand() bool {
+	if st := soilType(p.SoilType); st != "" {
+		return strings.HasPrefix(st, "sand")
+	}
+	return p.QcMPa > 0 && p.FsKPa/(p.QcMPa*1000) < sandRatioF
+}
+
+// friction is β2·fs at the point.
+func (p CPTPoint) friction() float64 {
+	b := interp1(beta2Fs, beta2Clay, p.FsKPa)
+	if p.sand() {
+		b = interp1(beta2Fs, beta2Sand, p.FsKPa)
+	}
+	return b * p.FsKPa
+}
+
+// cptResistance returns the shaft and toe resistance of the sounding
+// method, SP24 7.3.5: Rs = β1·qs with qs averaged from 1d above to 4d
+// below the toe, and the shaft friction Σ β2·fs·h in sublayers of at most
+// 2 m.
+func cptResistance(in Input, perimeter, d float64) (shaft, rs, qs, b1 float64, subs []ShaftSublayer, err error) {
+	pts := append([]CPTPoint{}, in.CPT...)
+	sort.Slice(pts, func(i, j int) bool { return pts[i].DepthM < pts[j].DepthM })
+	if len(pts) < 2 || pts[len(pts)-1].DepthM < in.ToeDepthM {
+		return 0, 0, 0, 0, nil, fmt.Errorf("sounding does not reach the pile toe")
+	}
+	top := math.Max(in.ToeDepthM-in.LengthM, pts[0].DepthM)
+	length := in.ToeDepthM - top
+	n := int(math.Ceil(length/maxSublayer - 1e-9))
+	h := length / float64(n)
+	for i := 0; i < n; i++ {
+		z0 := top + float64(i)*h
+		steps := int(math.Max(1, math.Round(h/cptStepM)))
+		f := 0.0
+		for j := 0; j < steps; j++ {
+			f += cptAt(pts, z0+(float64(j)+0.5)*h/float64(steps)).friction() / float64(steps)
+		}
+		r := f * perimeter * h
+		subs = append(subs, ShaftSublayer{FromDepthM: z0, ToDepthM: z0 + h, FiKPa: f, ResistanceKN: r})
+		shaft += r
+	}
+
+	z0 := in.ToeDepthM - d
+	z1 := math.Min(in.ToeDepthM+4*d, pts[len(pts)-1].DepthM)
+	steps := int(math.Max(1, math.Round((z1-z0)/cptStepM)))
+	for j := 0; j < steps; j++ {
+		qs += cptAt(pts, z0+(float64(j)+0.5)*(z1-z0)/float64(steps)).QcMPa / float64(steps)
+	}
+	b1 = interp1(beta1Qs, beta1, qs)
+	return shaft, b1 * qs * 1000, qs, b1, subs, nil
+}
+
+// ParseCPT reads a sounding from CSV or the first sheet of an xlsx with
+// the columns depth (m), qc (MPa), fs (kPa) and an optional soil type.
+// Header rows and rows that are not numbers are skipped.
+func ParseCPT(r io.Reader, name string) ([]CPTPoint, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx", ".xlsm":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if rows, err = f.GetRows(f.GetSheetName(0)); err != nil {
+			return nil, err
+		}
+	default:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		cr := csv.NewReader(bytes.NewReader(data))
+		cr.FieldsPerRecord = -1
+		// Semicolon and tab separated files come with decimal commas
+		switch {
+		case bytes.ContainsRune(data, ';'):
+			cr.Comma = ';'
+		case bytes.ContainsRune(data, '\t'):
+			cr.Comma = '\t'
+		}
+		if rows, err = cr.ReadAll(); err != nil {
+			return nil, err
+		}
+	}
+
+	var pts []CPTPoint
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		var v [3]float64
+		ok := true
+		for i := range v {
+			f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(row[i]), ",", "."), 64)
+			if err != nil {
+				ok = false
+				break
+			}
+			v[i] = f
+		}
+		if !ok {
+			continue
+		}
+		p := CPTPoint{DepthM: v[0], QcMPa: v[1], FsKPa: v[2]}
+		if len(row) > 3 {
+			p.SoilType = strings.TrimSpace(row[3])
+		}
+		pts = append(pts, p)
+	}
+	if len(pts) < 2 {
+		return nil, fmt.Errorf("no sounding data")
+	}
+	return pts, nil
+}
+
+type CPTResult struct {
+	Points    int     `json:"points"`
+	MeanQcMPa float64 `json:"mean_qc_mpa"`
+	Beta1     float64 `json:"beta1"`
+}
@@ -0,0 +1,44 @@
+package piles
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDowndragNoFrictionLayer(t *testing.T) {
+	il := 0.3
+	in := Input{
+		Method:       MethodSP24,
+		PileType:     "square",
+		SideM:        0.3,
+		LengthM:      12,
+		ToeDepthM:    12,
+		Installation: InstallDriven,
+		SurchargeKPa: 20,
+		LoadGKN:      500,
+		Layers: []Layer{
+			{FromDepthM: 0, ToDepthM: 4, SoilType: SoilSandMedium, Settling: true},
+			{FromDepthM: 4, ToDepthM: 15, SoilType: SoilClay, IL: &il},
+		},
+	}
+	settling, err := Calculate(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.Layers[0].NoFriction = true
+	got, err := Calculate(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DowndragKN <= 0 || math.Abs(got.DowndragKN-settling.DowndragKN) > 1e-9 {
+		t.Errorf("downdrag = %v, want %v", got.DowndragKN, settling.DowndragKN)
+	}
+	if math.Abs(got.DesignResistanceKN-settling.DesignResistanceKN) > 1e-9 {
+		t.Errorf("design resistance = %v, want %v", got.DesignResistanceKN, settling.DesignResistanceKN)
+	}
+	for _, sl := range got.ShaftSublayers {
+		if sl.ToDepthM <= 4+1e-9 && sl.ResistanceKN != 0 {
+			t.Errorf("friction %v kN in a layer without friction", sl.ResistanceKN)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package piles
+
+import (
+	"fmt"
+	"math"
+)
+
+// LateralInput is the horizontal force and moment at the pile head. A
+// fixed head is restrained against rotation by the cap. Eta1 and Eta2 are
+// η1 and η2 of the soil pressure check. η1 is 1 by default; η2 =
+// (Mc+Mt)/(n·Mc+Mt) depends on the share of permanent load and defaults to
+// 1/n, all of it permanent.
+type LateralInput struct {
+	HKN       float64 `json:"h_kn"`
+	MKNm      float64 `json:"m_knm"`
+	HeadFixed bool    `json:"head_fixed"`
+	Eta1      float64 `json:"eta1"`
+	Eta2      float64 `json:"eta2"`
+}
+
+type LateralPoint struct {
+	DepthM      float64 `json:"depth_m"`
+	DisplaceMM  float64 `json:"displace_mm"`
+	MomentKNm   float64 `json:"moment_knm"`
+	ShearKN     float64 `json:"shear_kn"`
+	PressureKPa float64 `json:"pressure_kpa"`
+}
+
+type PressureCheck struct {
+	DepthM      float64 `json:"depth_m"`
+	PressureKPa float64 `json:"pressure_kpa"`
+	LimitKPa    float64 `json:"limit_kpa"`
+	OK          bool    `json:"ok"`
+}
+
+type LateralResult struct {
+	AlphaE        float64         `json:"alpha_e"`
+	ReducedLength float64         `json:"reduced_length"`
+	WidthBpM      float64         `json:"width_bp_m"`
+	Eta2          float64         `json:"eta2"`
+	HeadDisplMM   float64         `json:"head_displ_mm"`
+	HeadRotation  float64         `json:"head_rotation_rad"`
+	HeadMomentKNm float64         `json:"head_moment_knm"`
+	MaxMomentKNm  float64         `json:"max_moment_knm"`
+	MaxMomentAtM  float64         `json:"max_moment_at_m"`
+	Profile       []LateralPoint  `json:"profile"`
+	Checks        []PressureCheck `json:"pressure_checks"`
+	OK            bool            `json:"ok"`
+}
+
+// gammaCLateral is γc of SP24 appendix В.
+const gammaCLateral = 3.0
+
+// etaN is n of η2, SP24 appendix В.
+const etaN = 2.5
+
+// lateralSegments is the number of finite difference steps along the pile.
+const lateralSegments = 200
+
+// lateral solves the pile as a beam on a Winkler foundation whose modulus
+// grows as K·z, SP24 appendix В, by finite differences along the pile.
+func lateral(in Input, lt LateralInput) (LateralResult, error) {
+	d := in.DiameterM
+	inertia := math.Pi * math.Pow(d, 4) / 64
+	if in.PileType == "square" {
+		d = in.SideM
+		inertia = math.Pow(d, 4) / 12
+	}
+	e := in.PileEMPa
+	if e <= 0 {
+		e = 30000
+	}
+	ei := e * 1000 * inertia
+	// Conventional width, SP24 В.5
+	bp := 1.5*d + 0.5
+	if d >= 0.8 {
+		bp = d + 1
+	}
+	if lt.Eta1 <= 0 {
+		lt.Eta1 = 1
+	}
+	if lt.Eta2 <= 0 {
+		lt.Eta2 = 1 / etaN
+	}
+
+	top := in.ToeDepthM - in.LengthM
+	ground := math.Max(top, 0)
+	// K of the upper hk = 3.5d + 1.5 m, averaged by thickness
+	hk := 3.5*d + 1.5
+	sk, sh := 0.0, 0.0
+	for _, l := range in.Layers {
+		h := math.Min(l.ToDepthM, ground+hk) - math.Max(l.FromDepthM, ground)
+		if h > 0 {
+			sk += l.KKNm4 * h
+			sh += h
+		}
+	}
+	if sk <= 0 {
+		return LateralResult{}, fmt.Errorf("no proportionality coefficient K")
+	}
+	res := LateralResult{WidthBpM: bp, Eta2: lt.Eta2}
+	res.AlphaE = math.Pow(sk/sh*bp/(gammaCLateral*ei), 0.2)
+	res.ReducedLength = res.AlphaE * in.LengthM
+
+	n := lateralSegments
+	h := in.LengthM / float64(n)
+	k := make([]float64, n+1)
+	for i := range k {
+		z := top + float64(i)*h
+		if l, ok := layerAtDepth(in.Layers, z); ok && z > ground {
+			k[i] = l.KKNm4 * (z - ground) * bp / gammaCLateral
+		}
+	}
+
+	// Unknowns y(-2)..y(n+2); row j of the system, column i+2 for y(i)
+	size := n + 5
+	a := make([][]float64, size)
+	for i := range a {
+		a[i] = make([]float64, size+1)
+	}
+	c := func(i int) int { return i + 2 }
+	h2, h3, h4 := h*h, h*h*h, h*h*h*h
+	for i := 0; i <= n; i++ {
+		row := a[i]
+		row[c(i-2)] += ei / h4
+		row[c(i-1)] -= 4 * ei / h4
+		row[c(i)] += 6*ei/h4 + k[i]
+		row[c(i+1)] -= 4 * ei / h4
+		row[c(i+2)] += ei / h4
+	}
+	// Head: shear H and either moment M or zero rotation. Positive H and M
+	// push the head the same way; moments are reported as EI·y''.
+	r := a[n+1]
+	r[c(-2)], r[c(-1)], r[c(1)], r[c(2)] = -1/(2*h3), 1/h3, -1/h3, 1/(2*h3)
+	r[size] = lt.HKN / ei
+	r = a[n+2]
+	if lt.HeadFixed {
+		r[c(-1)], r[c(1)] = -1/(2*h), 1/(2*h)
+	} else {
+		r[c(-1)], r[c(0)], r[c(1)] = 1/h2, -2/h2, 1/h2
+		r[size] = lt.MKNm / ei
+	}
+	// Free toe: no moment and no shear
+	r = a[n+3]
+	r[c(n-1)], r[c(n)], r[c(n+1)] = 1/h2, -2/h2, 1/h2
+	r = a[n+4]
+	r[c(n-2)], r[c(n-1)], r[c(n+1)], r[c(n+2)] = -1/(2*h3), 1/h3, -1/h3, 1/(2*h3)
+
+	y, err := solve(a)
+	if err != nil {
+		return LateralResult{}, err
+	}
+	at := func(i int) float64 { return y[c(i)] }
+
+	res.HeadDisplMM = at(0) * 1000
+	res.HeadRotation = (at(1) - at(-1)) / (2 * h)
+	for i := 0; i <= n; i++ {
+		p := LateralPoint{
+			DepthM:      top + float64(i)*h,
+			DisplaceMM:  at(i) * 1000,
+			MomentKNm:   ei * (at(i-1) - 2*at(i) + at(i+1)) / h2,
+			ShearKN:     ei * (-at(i-2) + 2*at(i-1) - 2*at(i+1) + at(i+2)) / (2 * h3),
+			PressureKPa: k[i] / bp * at(i),
+		}
+		res.Profile = append(res.Profile, p)
+		if math.Abs(p.MomentKNm) > math.Abs(res.MaxMomentKNm) {
+			res.MaxMomentKNm, res.MaxMomentAtM = p.MomentKNm, p.DepthM
+		}
+	}
+	res.HeadMomentKNm = res.Profile[0].MomentKNm
+
+	// Soil pressure is checked at z = 0.85/αε for long piles and at l/3 and
+	// l for short ones, SP24 В.9
+	depths := []float64{0.85 / res.AlphaE}
+	if res.ReducedLength <= 2.5 {
+		depths = []float64{in.LengthM / 3, in.LengthM}
+	}
+	xi := 0.3
+	if in.Installation == InstallDriven || in.Installation == "" {
+		xi = 0.6
+	}
+	res.OK = true
+	for _, z := range depths {
+		i := int(math.Round(math.Min(z, in.LengthM) / h))
+		zd := top + float64(i)*h
+		l, ok := layerAtDepth(in.Layers, zd)
+		if !ok {
+			continue
+		}
+		gz := 0.0
+		for _, s := range in.Layers {
+			gz += s.GammaKNM3 * math.Max(0, math.Min(zd, s.ToDepthM)-math.Max(s.FromDepthM, ground))
+		}
+		phi := l.PhiDeg * math.Pi / 180
+		limit := lt.Eta1 * lt.Eta2 * 4 / math.Cos(phi) * (gz*math.Tan(phi) + xi*l.CKPa)
+		pc := PressureCheck{DepthM: zd, PressureKPa: math.Abs(res.Profile[i].PressureKPa), LimitKPa: limit}
+		pc.OK = pc.PressureKPa <= limit
+		res.OK = res.OK && pc.OK
+		res.Checks = append(res.Checks, pc)
+	}
+	return res, nil
+}
+
+// solve runs Gaussian elimination with partial pivoting on the augmented
+// matrix a.
+func solve(a [][]float64) ([]float64, error) {
+	n := len(a)
+	for col := 0; col < n; col++ {
+		p := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[p][col]) {
+				p = r
+			}
+		}
+		if math.Abs(a[p][col]) < 1e-300 {
+			return nil, fmt.Errorf("singular system")
+		}
+		a[col], a[p] = a[p], a[col]
+		for r := col + 1; r < n; r++ {
+			f := a[r][col] / a[col][col]
+			if f == 0 {
+				continue
+			}
+			for j := col; j <= n; j++ {
+				a[r][j] -= f * a[col][j]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		s := a[r][n]
+		for j := r + 1; j < n; j++ {
+			s -= a[r][j] * x[j]
+		}
+		x[r] = s / a[r][r]
+	}
+	return x, nil
+}
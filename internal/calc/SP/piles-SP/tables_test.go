@@ -0,0 +1,50 @@
+package piles
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTableResistanceSand(t *testing.T) {
+	cases := []struct {
+		soil string
+		z    float64
+		want float64
+	}{
+		{SoilSandGravelly, 3, 7500},
+		{SoilSandCoarse, 3, 6600},
+		{SoilSandMedium, 3, 3100},
+		{SoilSandFine, 3, 2000},
+		{SoilSandSilty, 3, 1100},
+		{SoilSandMedium, 10, 4000},
+		{SoilSandFine, 10, 2600},
+		{SoilSandSilty, 10, 1500},
+		{SoilSandFine, 12.5, 2750},
+		{SoilSandSilty, 35, 2250},
+	}
+	for _, c := range cases {
+		got, err := tableResistance(Layer{SoilType: c.soil}, c.z, InstallDriven)
+		if err != nil {
+			t.Fatalf("%s at %v m: %v", c.soil, c.z, err)
+		}
+		if math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("%s at %v m: R = %v, want %v", c.soil, c.z, got, c.want)
+		}
+	}
+}
+
+func TestTableClayNeedsIL(t *testing.T) {
+	clay := Layer{SoilType: "Clay"}
+	if _, err := tableResistance(clay, 10, InstallDriven); err == nil {
+		t.Error("toe resistance of clay without IL")
+	}
+	if _, err := tableFriction(clay, 10); err == nil {
+		t.Error("friction of clay without IL")
+	}
+	il := 0.3
+	clay.IL = &il
+	got, err := tableResistance(clay, 10, InstallDriven)
+	if err != nil || math.Abs(got-3500) > 1e-9 {
+		t.Errorf("R = %v, %v; want 3500", got, err)
+	}
+}
@@ -0,0 +1,445 @@
+package piles
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// DefaultWorkbook is the reference workbook the export fills in.
+const DefaultWorkbook = "docs/Расчет свай фундамента .xlsm"
+
+// WorkbookInput places a pile calculation on the elevations of the
+// workbook: depths are measured down from GroundLevelM, the ground
+// surface in cell C13 of the borehole sheet.
+type WorkbookInput struct {
+	Input
+	GroundLevelM float64 `json:"ground_level_m"`
+	Borehole     string  `json:"borehole"`
+}
+
+// Sheets and input cells of the reference workbook. Formula cells are
+// left alone; Excel recalculates them on opening.
+const (
+	sheetGeology  = "Геология"
+	sheetBorehole = "Скважина"
+	sheetBearing  = "Несущая способность по грунту 1"
+	sheetLoads    = "Нагрузка на сваю"
+	sheetResults  = "Vertex"
+
+	geologyFirstRow  = 8
+	boreholeFirstRow = 8
+	boreholeLastRow  = 33
+
+	// The workbook takes unit weights as densities times this
+	gravity = 9.806
+)
+
+// Load rows of the stationary mode by group and the test mode rows, which
+// the export clears.
+var (
+	loadRowsG      = []int{6, 7, 8, 9, 10}
+	loadRowsQLong  = []int{13, 14, 15, 16, 17}
+	loadRowsQShort = []int{20}
+	loadRowsTest   = []int{23, 24, 25}
+)
+
+var sandGrains = map[string]string{
+	SoilSandGravelly: "гравелистые",
+	SoilSandCoarse:   "крупные",
+	SoilSandMedium:   "средней крупности",
+	SoilSandFine:     "мелкие",
+	SoilSandSilty:    "пылеватые",
+}
+
+var clayKinds = map[string]bool{"Супеси": true, "Суглинки": true, "Глины": true}
+
+// consistency names a loam by IL, GOST 25100.
+func consistency(il float64) string {
+	switch {
+	case il < 0:
+		return "твердые"
+	case il <= 0.25:
+		return "полутвердые"
+	case il <= 0.5:
+		return "тугопластичные"
+	case il <= 0.75:
+		return "мягкопластичные"
+	case il <= 1:
+		return "текучепластичные"
+	}
+	return "текучие"
+}
+
+// soilName returns the kind and grain size or consistency columns of the
+// geology sheet. Types outside the tables are written as the kind.
+func soilName(l Layer) (kind, grain string) {
+	st := soilType(l.SoilType)
+	if g, ok := sandGrains[st]; ok {
+		return "Пески", g
+	}
+	if st == SoilClay {
+		if l.IL == nil {
+			return "Суглинки", ""
+		}
+		return "Суглинки", consistency(*l.IL)
+	}
+	return l.SoilType, ""
+}
+
+// WriteWorkbook fills a copy of the workbook at path with the input and
+// adds a results sheet. The workbook takes at most 26 layers.
+func WriteWorkbook(w io.Writer, path string, in WorkbookInput, res Result) error {
+	n := len(in.Layers)
+	if n == 0 {
+		return fmt.Errorf("no layers provided")
+	}
+	if n > boreholeLastRow-boreholeFirstRow+1 {
+		return fmt.Errorf("too many layers for the workbook")
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	set := func(sheet, cell string, v interface{}) {
+		if err == nil {
+			err = f.SetCellValue(sheet, cell, v)
+		}
+	}
+	// A zero clears the cell so the sheet formulas see a blank
+	num := func(v float64) interface{} {
+		if v == 0 {
+			return nil
+		}
+		return v
+	}
+	at := func(col string, row int) string { return col + strconv.Itoa(row) }
+
+	// Geology, one element per layer
+	rows, err := f.GetRows(sheetGeology)
+	if err != nil {
+		return err
+	}
+	inputs := []string{"D", "E", "F", "G", "H", "I", "K", "L", "M", "N", "R", "S", "T", "U", "V", "W", "X", "Y", "AA", "AB"}
+	for r := geologyFirstRow; r <= max(len(rows), geologyFirstRow+n); r++ {
+		for _, c := range inputs {
+			set(sheetGeology, at(c, r), nil)
+		}
+	}
+	for i, l := range in.Layers {
+		r := geologyFirstRow + i
+		kind, grain := soilName(l)
+		set(sheetGeology, at("D", r), i+1)
+		set(sheetGeology, at("E", r), kind)
+		set(sheetGeology, at("F", r), grain)
+		for _, c := range []string{"L", "M", "N"} {
+			set(sheetGeology, at(c, r), num(l.GammaKNM3/gravity))
+		}
+		for _, c := range []string{"R", "S", "T"} {
+			set(sheetGeology, at(c, r), num(l.PhiDeg))
+		}
+		for _, c := range []string{"U", "V", "W"} {
+			set(sheetGeology, at(c, r), num(l.CKPa))
+		}
+		set(sheetGeology, at("X", r), num(l.EMPa))
+		if soilType(l.SoilType) == SoilClay && l.IL != nil {
+			set(sheetGeology, at("Y", r), *l.IL)
+		}
+		set(sheetGeology, at("AA", r), num(l.Poisson))
+		set(sheetGeology, at("AB", r), num(l.KKNm4))
+	}
+
+	// Borehole elevations
+	g := in.GroundLevelM
+	last := in.Layers[n-1]
+	set(sheetBorehole, "C7", in.Borehole)
+	set(sheetBorehole, "C8", g-in.ToeDepthM)
+	set(sheetBorehole, "C9", g-(in.ToeDepthM-in.LengthM))
+	set(sheetBorehole, "C11", last.ToDepthM-last.FromDepthM)
+	set(sheetBorehole, "C13", g)
+	for r := boreholeFirstRow; r <= boreholeLastRow; r++ {
+		i := r - boreholeFirstRow
+		if i >= n {
+			set(sheetBorehole, at("F", r), nil)
+			set(sheetBorehole, at("H", r), nil)
+			set(sheetBorehole, at("L", r), nil)
+			continue
+		}
+		friction := "Да"
+		if in.Layers[i].NoFriction {
+			friction = "Нет"
+		}
+		set(sheetBorehole, at("F", r), i+1)
+		set(sheetBorehole, at("H", r), g-in.Layers[i].FromDepthM)
+		set(sheetBorehole, at("L", r), friction)
+	}
+
+	// The bearing sheet is written for square driven piles
+	if in.PileType == "square" {
+		set(sheetBearing, "B32", in.SideM)
+	}
+
+	// Loads as one row per group with the factors of the method
+	gammaG, gammaQLong, gammaQShort, _ := factors(in.Method)
+	for _, c := range res.EC7 {
+		if c.Governing {
+			gammaG, gammaQLong, gammaQShort = c.GammaG, c.GammaQ, c.GammaQ
+		}
+	}
+	for _, rs := range [][]int{loadRowsG, loadRowsQLong, loadRowsQShort, loadRowsTest} {
+		for _, r := range rs {
+			for _, c := range []string{"C", "D", "E", "F", "G", "H", "I"} {
+				set(sheetLoads, at(c, r), nil)
+			}
+		}
+	}
+	loads := []struct {
+		row   int
+		name  string
+		v, gf float64
+	}{
+		{loadRowsG[0], "Постоянная нагрузка", in.LoadGKN, gammaG},
+		{loadRowsQLong[0], "Длительная нагрузка", in.LoadQLongKN, gammaQLong},
+		{loadRowsQShort[0], "Кратковременная нагрузка", in.LoadQShortKN, gammaQShort},
+	}
+	for _, l := range loads {
+		set(sheetLoads, at("C", l.row), l.name)
+		set(sheetLoads, at("D", l.row), l.v)
+		set(sheetLoads, at("F", l.row), 1)
+		set(sheetLoads, at("H", l.row), l.gf)
+		set(sheetLoads, at("I", l.row), 1)
+	}
+	set(sheetLoads, "D33", res.PileCount)
+	if err != nil {
+		return err
+	}
+
+	if err := writeResults(f, in, res); err != nil {
+		return err
+	}
+	// Drop the cached values so Excel recalculates every formula
+	if err := f.UpdateLinkedValue(); err != nil {
+		return err
+	}
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// writeResults replaces the results sheet with the input, the layer table
+// and the results of the calculation.
+func writeResults(f *excelize.File, in WorkbookInput, res Result) error {
+	if idx, _ := f.GetSheetIndex(sheetResults); idx >= 0 {
+		if err := f.DeleteSheet(sheetResults); err != nil {
+			return err
+		}
+	}
+	if _, err := f.NewSheet(sheetResults); err != nil {
+		return err
+	}
+	row := 1
+	var err error
+	put := func(vals ...interface{}) {
+		if err == nil {
+			err = f.SetSheetRow(sheetResults, "A"+strconv.Itoa(row), &vals)
+		}
+		row++
+	}
+	size := in.DiameterM
+	if in.PileType == "square" {
+		size = in.SideM
+	}
+	method := in.Method
+	if method == "" {
+		method = MethodSP24
+	}
+
+	put("Исходные данные")
+	put("Метод", string(method))
+	put("Тип сваи", in.PileType)
+	put("Сторона/диаметр, м", size)
+	put("Длина, м", in.LengthM)
+	put("Глубина пяты, м", in.ToeDepthM)
+	put("Отметка поверхности, м", in.GroundLevelM)
+	put("Способ погружения", res.Installation)
+	put("Постоянная нагрузка, кН", in.LoadGKN)
+	put("Длительная нагрузка, кН", in.LoadQLongKN)
+	put("Кратковременная нагрузка, кН", in.LoadQShortKN)
+	row++
+
+	put("Слои")
+	put("От, м", "До, м", "Грунт", "γ, кН/м3", "IL", "φ, °", "c, кПа", "E, МПа", "ν", "K, кН/м4", "Трение")
+	for _, l := range in.Layers {
+		friction := "Да"
+		if l.NoFriction {
+			friction = "Нет"
+		}
+		var il interface{}
+		if l.IL != nil {
+			il = *l.IL
+		}
+		put(l.FromDepthM, l.ToDepthM, l.SoilType, l.GammaKNM3, il, l.PhiDeg, l.CKPa, l.EMPa, l.Poisson, l.KKNm4, friction)
+	}
+	row++
+
+	put("Результаты")
+	put("Расчётная нагрузка, кН", res.DesignLoadKN)
+	put("Сопротивление по боковой поверхности, кН", res.ShaftResistanceKN)
+	put("Сопротивление под нижним концом, кН", res.BaseResistanceKN)
+	put("R, кПа", res.BaseRKPa)
+	put("γcR", res.GammaCR)
+	put("γcf", res.GammaCf)
+	put("Расчётное сопротивление сваи, кН", res.DesignResistanceKN)
+	put("Количество свай", res.PileCount)
+	put("Нагрузка на сваю, кН", res.AverageLoadPerPile)
+	if res.SettlementMM > 0 {
+		put("Осадка сваи, мм", res.SettlementMM)
+		put("Осадка куста, мм", res.GroupSettlementMM)
+		put("Предельная осадка, мм", res.SettlementLimitMM)
+	}
+	if res.DowndragKN > 0 {
+		put("Нейтральная плоскость, м", res.NeutralPlaneM)
+		put("Отрицательное трение, кН", res.DowndragKN)
+	}
+	put("Примечание", res.Notes)
+	row++
+
+	put("Подслои боковой поверхности")
+	put("От, м", "До, м", "fi, кПа", "Сопротивление, кН", "Отрицательное трение, кН")
+	for _, s := range res.ShaftSublayers {
+		put(s.FromDepthM, s.ToDepthM, s.FiKPa, s.ResistanceKN, s.DowndragKN)
+	}
+	if err != nil {
+		return err
+	}
+	return f.SetColWidth(sheetResults, "A", "A", 42)
+}
+
+// roundMM drops the float noise of differences of elevations.
+func roundMM(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// ParseWorkbook reads the layers, pile and loads from a filled-in
+// workbook. Loads are the normative sums of the stationary mode; the pile
+// is square and driven, as the workbook assumes.
+func ParseWorkbook(r io.Reader) (WorkbookInput, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return WorkbookInput{}, err
+	}
+	defer f.Close()
+
+	num := func(sheet, cell string) float64 {
+		s, _ := f.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+		v, _ := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+		return v
+	}
+	str := func(sheet, cell string) string {
+		s, _ := f.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+		return strings.TrimSpace(s)
+	}
+	at := func(col string, row int) string { return col + strconv.Itoa(row) }
+
+	// Soil elements by their ИГЭ label
+	geology := map[string]Layer{}
+	rows, err := f.GetRows(sheetGeology)
+	if err != nil {
+		return WorkbookInput{}, err
+	}
+	for r := geologyFirstRow; r <= len(rows); r++ {
+		id := str(sheetGeology, at("D", r))
+		if id == "" {
+			continue
+		}
+		kind, grain := str(sheetGeology, at("E", r)), strings.ToLower(str(sheetGeology, at("F", r)))
+		l := Layer{
+			SoilType:  kind,
+			GammaKNM3: num(sheetGeology, at("M", r)) * gravity,
+			PhiDeg:    num(sheetGeology, at("S", r)),
+			CKPa:      num(sheetGeology, at("V", r)),
+			EMPa:      num(sheetGeology, at("X", r)),
+			Poisson:   num(sheetGeology, at("AA", r)),
+			KKNm4:     num(sheetGeology, at("AB", r)),
+		}
+		if str(sheetGeology, at("Y", r)) != "" {
+			il := num(sheetGeology, at("Y", r))
+			l.IL = &il
+		}
+		switch {
+		case kind == "Пески":
+			for st, g := range sandGrains {
+				if g == grain {
+					l.SoilType = st
+				}
+			}
+		case clayKinds[kind]:
+			l.SoilType = SoilClay
+		}
+		geology[id] = l
+	}
+
+	var in WorkbookInput
+	in.Borehole = str(sheetBorehole, "C7")
+	// Ground level is C13 as exported; older workbooks leave it blank and
+	// start the first layer at the ground
+	in.GroundLevelM = num(sheetBorehole, "C13")
+	if str(sheetBorehole, "C13") == "" {
+		in.GroundLevelM = num(sheetBorehole, at("H", boreholeFirstRow))
+	}
+	var tops []float64
+	for r := boreholeFirstRow; r <= boreholeLastRow; r++ {
+		id := str(sheetBorehole, at("F", r))
+		if id == "" || id == "0" {
+			break
+		}
+		l, ok := geology[id]
+		if !ok {
+			return WorkbookInput{}, fmt.Errorf("unknown soil element %q", id)
+		}
+		l.NoFriction = str(sheetBorehole, at("L", r)) == "Нет"
+		tops = append(tops, num(sheetBorehole, at("H", r)))
+		in.Layers = append(in.Layers, l)
+	}
+	if len(in.Layers) == 0 {
+		return WorkbookInput{}, fmt.Errorf("no layers in the workbook")
+	}
+	for i := range in.Layers {
+		bottom := tops[i] - num(sheetBorehole, "C11")
+		if i+1 < len(tops) {
+			bottom = tops[i+1]
+		}
+		in.Layers[i].FromDepthM = roundMM(in.GroundLevelM - tops[i])
+		in.Layers[i].ToDepthM = roundMM(in.GroundLevelM - bottom)
+	}
+
+	toe, head := num(sheetBorehole, "C8"), num(sheetBorehole, "C9")
+	in.Method = MethodSP24
+	in.PileType = "square"
+	in.SideM = num(sheetBearing, "B32")
+	in.Installation = InstallDriven
+	in.ToeDepthM = roundMM(in.GroundLevelM - toe)
+	in.LengthM = roundMM(head - toe)
+
+	// Qs·S + Qc·C with γfII of each row
+	sum := func(rows []int) float64 {
+		s := 0.0
+		for _, r := range rows {
+			gf := num(sheetLoads, at("I", r))
+			if str(sheetLoads, at("I", r)) == "" {
+				gf = 1
+			}
+			s += (num(sheetLoads, at("D", r))*num(sheetLoads, at("F", r)) + num(sheetLoads, at("E", r))*num(sheetLoads, at("G", r))) * gf
+		}
+		return s
+	}
+	in.LoadGKN = sum(loadRowsG)
+	in.LoadQLongKN = sum(loadRowsQLong)
+	in.LoadQShortKN = sum(loadRowsQShort)
+	return in, nil
+}
@@ -6,42 +6,219 @@ import (
 )
 
 type Input struct {
+	// Product selects a catalog anchor by id; its approval values replace
+	// FyMPa, GammaM and the CCD defaults.
+	Product        string  `json:"product"`
 	BoltDiameterMM float64 `json:"bolt_diameter_mm"`
 	BoltCount      int     `json:"bolt_count"`
 	FyMPa          float64 `json:"fy_mpa"`
 	GammaM         float64 `json:"gamma_m"`
 	TensionKN      float64 `json:"tension_kn"`
-	ShearKN        float64 `json:"shear_kn"`
+	// ShearKN acts in +y, towards EdgeTopMM.
+	ShearKN float64 `json:"shear_kn"`
+
+	AnchorType        AnchorType `json:"anchor_type"`
+	HeadDiameterMM    float64    `json:"head_diameter_mm"`
+	EmbedmentMM       float64    `json:"embedment_mm"`
+	ConcreteClass     string     `json:"concrete_class"`
+	FckMPa            float64    `json:"fck_mpa"`
+	Cracked           bool       `json:"cracked"`
+	MemberThicknessMM float64    `json:"member_thickness_mm"`
+	GammaMc           float64    `json:"gamma_mc"`
+	Columns           int        `json:"columns"`
+	SpacingXMM        float64    `json:"spacing_x_mm"`
+	SpacingYMM        float64    `json:"spacing_y_mm"`
+	EdgeLeftMM        float64    `json:"edge_left_mm"`
+	EdgeRightMM       float64    `json:"edge_right_mm"`
+	EdgeBottomMM      float64    `json:"edge_bottom_mm"`
+	EdgeTopMM         float64    `json:"edge_top_mm"`
 }
 
 type Result struct {
 	TensionCapacityKN float64 `json:"tension_capacity_kn"`
 	ShearCapacityKN   float64 `json:"shear_capacity_kn"`
+	ConeKN            float64 `json:"cone_kn,omitempty"`
+	PullOutKN         float64 `json:"pull_out_kn,omitempty"`
+	SplittingKN       float64 `json:"splitting_kn,omitempty"`
+	EdgeKN            float64 `json:"edge_kn,omitempty"`
+	PryOutKN          float64 `json:"pry_out_kn,omitempty"`
+	TensionGoverning  string  `json:"tension_governing"`
+	ShearGoverning    string  `json:"shear_governing"`
 	Utilization       float64 `json:"utilization"`
 	OK                bool    `json:"ok"`
 	Notes             string  `json:"notes"`
 }
 
 func Calculate(in Input) (Result, error) {
-	if in.BoltDiameterMM <= 0 || in.BoltCount <= 0 || in.FyMPa <= 0 {
+	var v *Variant
+	var product Product
+	if in.Product != "" {
+		var err error
+		if product, v, err = findVariant(in.Product, in.BoltDiameterMM, in.EmbedmentMM); err != nil {
+			return Result{}, err
+		}
+		in.EmbedmentMM = v.EmbedmentMM
+		in.AnchorType = AnchorPostInstalled
+	}
+	if in.BoltDiameterMM <= 0 || in.BoltCount <= 0 || (in.FyMPa <= 0 && v == nil) {
 		return Result{}, fmt.Errorf("invalid input")
 	}
 	if in.GammaM <= 0 {
 		in.GammaM = 1.25
 	}
-	area := math.Pi * in.BoltDiameterMM * in.BoltDiameterMM / 4.0 // mm2
 	n := float64(in.BoltCount)
-	nrd := n * area * in.FyMPa / in.GammaM / 1000.0 // kN
-	vrd := n * 0.6 * area * in.FyMPa / in.GammaM / 1000.0
+	nrd1, vrd1 := steelResistance(in.BoltDiameterMM, in.FyMPa, in.GammaM, v)
+	nrd := n * nrd1 // kN
+	vrd := n * vrd1
 	util := 0.0
 	if nrd > 0 && vrd > 0 {
 		util = math.Pow(in.TensionKN/nrd, 2) + math.Pow(in.ShearKN/vrd, 2)
 	}
-	return Result{
+	res := Result{
 		TensionCapacityKN: nrd,
 		ShearCapacityKN:   vrd,
+		TensionGoverning:  "steel",
+		ShearGoverning:    "steel",
 		Utilization:       util,
-		OK:                util <= 1.0,
 		Notes:             "Simplified steel bolt check (no concrete failure modes).",
-	}, nil
+	}
+	if in.EmbedmentMM > 0 {
+		ci, err := concreteParams(in, v)
+		if err != nil {
+			return Result{}, err
+		}
+		g, err := gridLayout(in)
+		if err != nil {
+			return Result{}, err
+		}
+		res.ConeKN, res.PullOutKN, res.SplittingKN = concreteTension(ci, g, 0, 0)
+		res.PryOutKN = pryOut(ci.hef, res.ConeKN)
+		if in.ShearKN > 0 {
+			res.EdgeKN = concreteEdge(ci, g, 0, 1)
+		}
+		nrdC, tGov := minResistance(map[string]float64{
+			"steel":     nrd,
+			"cone":      res.ConeKN,
+			"pull-out":  res.PullOutKN,
+			"splitting": res.SplittingKN,
+		})
+		vrdC, vGov := minResistance(map[string]float64{
+			"steel":   vrd,
+			"edge":    res.EdgeKN,
+			"pry-out": res.PryOutKN,
+		})
+		res.TensionGoverning = tGov
+		res.ShearGoverning = vGov
+		res.Utilization = math.Max(util, interaction(in.TensionKN/nrdC, in.ShearKN/vrdC))
+		res.Notes = "Steel and concrete capacity design (cone, pull-out, splitting, edge, pry-out)."
+		if v != nil {
+			res.Notes = productNote(product) + " Concrete capacity design."
+			if msg := productLimits(v, g, in.MemberThicknessMM); msg != "" {
+				res.Notes += msg
+				res.OK = false
+				return res, nil
+			}
+		}
+	}
+	res.OK = res.Utilization <= 1.0
+	return res, nil
+}
+
+func concreteParams(in Input, v *Variant) (concreteInput, error) {
+	fck := in.FckMPa
+	if fck <= 0 {
+		var err error
+		if fck, err = ParseConcreteClass(in.ConcreteClass); err != nil {
+			return concreteInput{}, err
+		}
+	}
+	if in.AnchorType == "" {
+		in.AnchorType = AnchorCastIn
+	}
+	if in.AnchorType != AnchorCastIn && in.AnchorType != AnchorPostInstalled {
+		return concreteInput{}, fmt.Errorf("invalid anchor type")
+	}
+	if in.GammaMc <= 0 {
+		in.GammaMc = 1.5
+	}
+	ci := concreteInput{
+		anchorType: in.AnchorType,
+		d:          in.BoltDiameterMM,
+		dh:         in.HeadDiameterMM,
+		hef:        in.EmbedmentMM,
+		h:          in.MemberThicknessMM,
+		fck:        fck,
+		cracked:    in.Cracked,
+		gammaMc:    in.GammaMc,
+	}
+	if v != nil {
+		ci.k1 = v.KUncracked
+		if in.Cracked {
+			ci.k1 = v.KCracked
+		}
+		ci.ccrN = v.EdgeCrMM
+		po, err := v.pullOut(fck, in.Cracked)
+		if err != nil {
+			return concreteInput{}, err
+		}
+		ci.pullOut = po / in.GammaMc
+	}
+	return ci, nil
+}
+
+// gridLayout places BoltCount anchors in a rectangular grid of Columns
+// columns. Zero edge distances mean the edge is far away.
+func gridLayout(in Input) (layout, error) {
+	cols := in.Columns
+	if cols <= 0 {
+		cols = in.BoltCount
+	}
+	if in.BoltCount%cols != 0 {
+		return layout{}, fmt.Errorf("bolt count is not a multiple of columns")
+	}
+	rows := in.BoltCount / cols
+	if (cols > 1 && in.SpacingXMM <= 0) || (rows > 1 && in.SpacingYMM <= 0) {
+		return layout{}, fmt.Errorf("invalid spacing")
+	}
+	g := layout{}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			g.pts = append(g.pts, [2]float64{float64(c) * in.SpacingXMM, float64(r) * in.SpacingYMM})
+		}
+	}
+	w := float64(cols-1) * in.SpacingXMM
+	h := float64(rows-1) * in.SpacingYMM
+	g.xMin = edgeAt(0, in.EdgeLeftMM, -1)
+	g.xMax = edgeAt(w, in.EdgeRightMM, 1)
+	g.yMin = edgeAt(0, in.EdgeBottomMM, -1)
+	g.yMax = edgeAt(h, in.EdgeTopMM, 1)
+	return g, nil
+}
+
+// edgeAt returns the coordinate of an edge at distance dist from pos in
+// direction dir, or infinity in that direction when no edge is given.
+func edgeAt(pos, dist float64, dir int) float64 {
+	if dist > 0 {
+		return pos + float64(dir)*dist
+	}
+	return math.Inf(dir)
+}
+
+func minResistance(r map[string]float64) (float64, string) {
+	best, name := math.Inf(1), ""
+	for _, k := range []string{"steel", "cone", "pull-out", "splitting", "edge", "pry-out"} {
+		if v, ok := r[k]; ok && v > 0 && v < best {
+			best, name = v, k
+		}
+	}
+	return best, name
+}
+
+// interaction combines tension and shear utilizations of the concrete
+// failure modes.
+func interaction(bN, bV float64) float64 {
+	if bN > 1 || bV > 1 {
+		return math.Max(bN, bV)
+	}
+	return math.Pow(bN, 1.5) + math.Pow(bV, 1.5)
 }
@@ -0,0 +1,413 @@
+package anchors
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type Anchor struct {
+	XMM float64 `json:"x_mm"`
+	YMM float64 `json:"y_mm"`
+}
+
+// PlateInput describes a rigid base plate centred at the origin with anchors
+// at given coordinates. N is positive in tension; Mx gives tension at +y and
+// My tension at +x. Edge distances are measured from the outermost anchor.
+type PlateInput struct {
+	Product        string   `json:"product"`
+	BoltDiameterMM float64  `json:"bolt_diameter_mm"`
+	FyMPa          float64  `json:"fy_mpa"`
+	GammaM         float64  `json:"gamma_m"`
+	Anchors        []Anchor `json:"anchors"`
+	PlateBMM       float64  `json:"plate_b_mm"`
+	PlateLMM       float64  `json:"plate_l_mm"`
+	BearingFactor  float64  `json:"bearing_factor"`
+
+	NKN   float64 `json:"n_kn"`
+	VxKN  float64 `json:"vx_kn"`
+	VyKN  float64 `json:"vy_kn"`
+	MxKNm float64 `json:"mx_knm"`
+	MyKNm float64 `json:"my_knm"`
+	TKNm  float64 `json:"t_knm"`
+
+	AnchorType        AnchorType `json:"anchor_type"`
+	HeadDiameterMM    float64    `json:"head_diameter_mm"`
+	EmbedmentMM       float64    `json:"embedment_mm"`
+	ConcreteClass     string     `json:"concrete_class"`
+	FckMPa            float64    `json:"fck_mpa"`
+	Cracked           bool       `json:"cracked"`
+	MemberThicknessMM float64    `json:"member_thickness_mm"`
+	GammaMc           float64    `json:"gamma_mc"`
+	EdgeLeftMM        float64    `json:"edge_left_mm"`
+	EdgeRightMM       float64    `json:"edge_right_mm"`
+	EdgeBottomMM      float64    `json:"edge_bottom_mm"`
+	EdgeTopMM         float64    `json:"edge_top_mm"`
+}
+
+type AnchorForce struct {
+	XMM       float64 `json:"x_mm"`
+	YMM       float64 `json:"y_mm"`
+	TensionKN float64 `json:"tension_kn"`
+	ShearKN   float64 `json:"shear_kn"`
+}
+
+type PlateResult struct {
+	Anchors             []AnchorForce `json:"anchors"`
+	CriticalAnchor      int           `json:"critical_anchor"`
+	GroupTensionKN      float64       `json:"group_tension_kn"`
+	CompressionKN       float64       `json:"compression_kn"`
+	BearingStressMPa    float64       `json:"bearing_stress_mpa"`
+	BearingCapacityMPa  float64       `json:"bearing_capacity_mpa"`
+	SteelUtilization    float64       `json:"steel_utilization"`
+	ConeKN              float64       `json:"cone_kn,omitempty"`
+	PullOutKN           float64       `json:"pull_out_kn,omitempty"`
+	SplittingKN         float64       `json:"splitting_kn,omitempty"`
+	EdgeXKN             float64       `json:"edge_x_kn,omitempty"`
+	EdgeYKN             float64       `json:"edge_y_kn,omitempty"`
+	PryOutKN            float64       `json:"pry_out_kn,omitempty"`
+	ConcreteUtilization float64       `json:"concrete_utilization"`
+	Utilization         float64       `json:"utilization"`
+	OK                  bool          `json:"ok"`
+	Notes               string        `json:"notes"`
+}
+
+// Design compressive strength Rb by class B, SP63 table 6.8 (MPa).
+var rbByClass = map[float64]float64{
+	10: 6.0, 15: 8.5, 20: 11.5, 25: 14.5, 30: 17.0,
+	35: 19.5, 40: 22.0, 45: 25.0, 50: 27.5, 55: 30.0, 60: 33.0,
+}
+
+const (
+	steelEs    = 2.0e5
+	plateCells = 40
+)
+
+func CalculatePlate(in PlateInput) (PlateResult, error) {
+	var pv *Variant
+	var product Product
+	if in.Product != "" {
+		var err error
+		if product, pv, err = findVariant(in.Product, in.BoltDiameterMM, in.EmbedmentMM); err != nil {
+			return PlateResult{}, err
+		}
+		in.EmbedmentMM = pv.EmbedmentMM
+		in.AnchorType = AnchorPostInstalled
+	}
+	if in.BoltDiameterMM <= 0 || (in.FyMPa <= 0 && pv == nil) || len(in.Anchors) == 0 {
+		return PlateResult{}, fmt.Errorf("invalid input")
+	}
+	if in.PlateBMM <= 0 || in.PlateLMM <= 0 {
+		return PlateResult{}, fmt.Errorf("invalid plate")
+	}
+	if in.GammaM <= 0 {
+		in.GammaM = 1.25
+	}
+	if in.BearingFactor <= 0 {
+		in.BearingFactor = 1.0
+	}
+	fck := in.FckMPa
+	if fck <= 0 {
+		var err error
+		if fck, err = ParseConcreteClass(in.ConcreteClass); err != nil {
+			return PlateResult{}, err
+		}
+	}
+	rb := designStrength(in.ConcreteClass, fck)
+	ec := 4700 * math.Sqrt(fck)
+
+	as := math.Pi * in.BoltDiameterMM * in.BoltDiameterMM / 4.0
+	tensions, sigma, comp, err := plateEquilibrium(in, as*steelEs/ec)
+	if err != nil {
+		return PlateResult{}, err
+	}
+
+	// Shear and torsion shared by all anchors elastically
+	pts := make([][2]float64, len(in.Anchors))
+	for i, a := range in.Anchors {
+		pts[i] = [2]float64{a.XMM, a.YMM}
+	}
+	shears := shareShear(pts, in.VxKN, in.VyKN, in.TKNm)
+
+	nrd, vrd := steelResistance(in.BoltDiameterMM, in.FyMPa, in.GammaM, pv)
+	res := PlateResult{
+		CompressionKN:      comp,
+		BearingStressMPa:   sigma,
+		BearingCapacityMPa: in.BearingFactor * rb,
+	}
+	crit := 0
+	var tensioned [][2]float64
+	sumT, sumTx, sumTy := 0.0, 0.0, 0.0
+	for i, a := range in.Anchors {
+		f := AnchorForce{XMM: a.XMM, YMM: a.YMM, TensionKN: tensions[i], ShearKN: shears[i]}
+		res.Anchors = append(res.Anchors, f)
+		u := math.Pow(f.TensionKN/nrd, 2) + math.Pow(f.ShearKN/vrd, 2)
+		if u > res.SteelUtilization {
+			res.SteelUtilization = u
+			crit = i
+		}
+		if f.TensionKN > 0 {
+			tensioned = append(tensioned, pts[i])
+			sumT += f.TensionKN
+			sumTx += f.TensionKN * a.XMM
+			sumTy += f.TensionKN * a.YMM
+		}
+	}
+	res.CriticalAnchor = crit + 1
+	res.GroupTensionKN = sumT
+	res.Utilization = math.Max(res.SteelUtilization, sigma/res.BearingCapacityMPa)
+	res.Notes = "Rigid base plate with elastic anchors and no-tension bearing; steel and bearing checks only."
+
+	if in.EmbedmentMM > 0 {
+		ci, err := concreteParams(Input{
+			BoltDiameterMM:    in.BoltDiameterMM,
+			AnchorType:        in.AnchorType,
+			HeadDiameterMM:    in.HeadDiameterMM,
+			EmbedmentMM:       in.EmbedmentMM,
+			FckMPa:            fck,
+			Cracked:           in.Cracked,
+			MemberThicknessMM: in.MemberThicknessMM,
+			GammaMc:           in.GammaMc,
+		}, pv)
+		if err != nil {
+			return PlateResult{}, err
+		}
+		all := pointsLayout(pts, in)
+		bN := 0.0
+		if sumT > 0 {
+			tg := pointsLayout(tensioned, in)
+			tg.xMin, tg.xMax, tg.yMin, tg.yMax = all.xMin, all.xMax, all.yMin, all.yMax
+			cx, cy := centroid(tensioned)
+			ex := math.Abs(sumTx/sumT - cx)
+			ey := math.Abs(sumTy/sumT - cy)
+			var pullOne float64
+			res.ConeKN, pullOne, res.SplittingKN = concreteTension(ci, tg, ex, ey)
+			bN = sumT / res.ConeKN
+			if res.SplittingKN > 0 {
+				bN = math.Max(bN, sumT/res.SplittingKN)
+			}
+			if pullOne > 0 {
+				// Pull-out is checked on the most loaded anchor
+				res.PullOutKN = pullOne / float64(len(tensioned))
+				bN = math.Max(bN, res.Anchors[maxTension(res.Anchors)].TensionKN/res.PullOutKN)
+			}
+		}
+		coneAll, _, _ := concreteTension(ci, all, 0, 0)
+		res.PryOutKN = pryOut(ci.hef, coneAll)
+		v := math.Hypot(in.VxKN, in.VyKN)
+		bV := 0.0
+		if v > 0 {
+			bV = v / res.PryOutKN
+		}
+		if in.VxKN != 0 {
+			if res.EdgeXKN = concreteEdge(ci, all, math.Copysign(1, in.VxKN), 0); res.EdgeXKN > 0 {
+				bV = math.Max(bV, math.Abs(in.VxKN)/res.EdgeXKN)
+			}
+		}
+		if in.VyKN != 0 {
+			if res.EdgeYKN = concreteEdge(ci, all, 0, math.Copysign(1, in.VyKN)); res.EdgeYKN > 0 {
+				bV = math.Max(bV, math.Abs(in.VyKN)/res.EdgeYKN)
+			}
+		}
+		res.ConcreteUtilization = interaction(bN, bV)
+		res.Utilization = math.Max(res.Utilization, res.ConcreteUtilization)
+		res.Notes = "Rigid base plate with elastic anchors and no-tension bearing; concrete failure modes checked for the tensioned anchors and the whole group in shear."
+		if pv != nil {
+			res.Notes += " " + productNote(product)
+			if msg := productLimits(pv, all, in.MemberThicknessMM); msg != "" {
+				res.Notes += msg
+				res.OK = false
+				return res, nil
+			}
+		}
+	}
+	res.OK = res.Utilization <= 1.0
+	return res, nil
+}
+
+// plateEquilibrium solves the linear stress field of the rigid plate so that
+// anchors (tension only, stiffness kA = As*Es/Ec) and concrete (compression
+// only) balance N, Mx, My. Returns anchor tensions (kN), peak bearing
+// stress (MPa) and the bearing resultant (kN).
+func plateEquilibrium(in PlateInput, kA float64) ([]float64, float64, float64, error) {
+	target := [3]float64{in.NKN * 1000, in.MxKNm * 1e6, in.MyKNm * 1e6}
+	dx := in.PlateBMM / plateCells
+	dy := in.PlateLMM / plateCells
+	dA := dx * dy
+
+	// eval returns the resisting forces and the tangent stiffness for the
+	// concrete stress plane e = c[0] + c[1]*y + c[2]*x (MPa, negative in
+	// compression); an anchor carries kA*e.
+	eval := func(c [3]float64) ([3]float64, [3][3]float64) {
+		var f [3]float64
+		var k [3][3]float64
+		add := func(w, x, y, s float64) {
+			v := [3]float64{1, y, x}
+			for i := 0; i < 3; i++ {
+				f[i] += s * v[i]
+				for j := 0; j < 3; j++ {
+					k[i][j] += w * v[i] * v[j]
+				}
+			}
+		}
+		for _, a := range in.Anchors {
+			e := c[0] + c[1]*a.YMM + c[2]*a.XMM
+			if e > 0 {
+				add(kA, a.XMM, a.YMM, kA*e)
+			}
+		}
+		for i := 0; i < plateCells; i++ {
+			x := -in.PlateBMM/2 + (float64(i)+0.5)*dx
+			for j := 0; j < plateCells; j++ {
+				y := -in.PlateLMM/2 + (float64(j)+0.5)*dy
+				e := c[0] + c[1]*y + c[2]*x
+				if e < 0 {
+					add(dA, x, y, dA*e)
+				}
+			}
+		}
+		return f, k
+	}
+
+	// Start from a fully compressed plate so the stiffness is not singular
+	var c [3]float64
+	c[0] = -1e-6
+	converged := false
+	for iter := 0; iter < 200; iter++ {
+		f, k := eval(c)
+		r := [3]float64{target[0] - f[0], target[1] - f[1], target[2] - f[2]}
+		if math.Abs(r[0]) < 1e-3*(1+math.Abs(target[0])) && math.Abs(r[1]) < 1e-3*(1+math.Abs(target[1])) && math.Abs(r[2]) < 1e-3*(1+math.Abs(target[2])) {
+			converged = true
+			break
+		}
+		for i := 0; i < 3; i++ {
+			k[i][i] += 1e-9 * (1 + k[i][i])
+		}
+		step, ok := solve3(k, r)
+		if !ok {
+			return nil, 0, 0, fmt.Errorf("base plate is unstable under the given loads")
+		}
+		for i := range c {
+			c[i] += step[i]
+		}
+	}
+	if !converged {
+		return nil, 0, 0, fmt.Errorf("base plate equilibrium did not converge")
+	}
+
+	tensions := make([]float64, len(in.Anchors))
+	for i, a := range in.Anchors {
+		if e := c[0] + c[1]*a.YMM + c[2]*a.XMM; e > 0 {
+			tensions[i] = kA * e / 1000.0
+		}
+	}
+	peak, comp := 0.0, 0.0
+	for _, x := range []float64{-in.PlateBMM / 2, in.PlateBMM / 2} {
+		for _, y := range []float64{-in.PlateLMM / 2, in.PlateLMM / 2} {
+			if e := c[0] + c[1]*y + c[2]*x; e < 0 {
+				peak = math.Max(peak, -e)
+			}
+		}
+	}
+	for i := 0; i < plateCells; i++ {
+		x := -in.PlateBMM/2 + (float64(i)+0.5)*dx
+		for j := 0; j < plateCells; j++ {
+			y := -in.PlateLMM/2 + (float64(j)+0.5)*dy
+			if e := c[0] + c[1]*y + c[2]*x; e < 0 {
+				comp -= e * dA
+			}
+		}
+	}
+	return tensions, peak, comp / 1000.0, nil
+}
+
+func solve3(a [3][3]float64, b [3]float64) ([3]float64, bool) {
+	det := a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) -
+		a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) +
+		a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+	if det == 0 || math.IsNaN(det) {
+		return [3]float64{}, false
+	}
+	var x [3]float64
+	for col := 0; col < 3; col++ {
+		m := a
+		for row := 0; row < 3; row++ {
+			m[row][col] = b[row]
+		}
+		x[col] = (m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])) / det
+	}
+	return x, true
+}
+
+// shareShear distributes Vx, Vy and torsion T (kN*m) about the group
+// centroid between the anchors.
+func shareShear(pts [][2]float64, vx, vy, t float64) []float64 {
+	n := float64(len(pts))
+	cx, cy := centroid(pts)
+	sumR2 := 0.0
+	for _, p := range pts {
+		sumR2 += (p[0]-cx)*(p[0]-cx) + (p[1]-cy)*(p[1]-cy)
+	}
+	out := make([]float64, len(pts))
+	for i, p := range pts {
+		fx := vx / n
+		fy := vy / n
+		if sumR2 > 0 {
+			fx -= t * 1000 * (p[1] - cy) / sumR2
+			fy += t * 1000 * (p[0] - cx) / sumR2
+		}
+		out[i] = math.Hypot(fx, fy)
+	}
+	return out
+}
+
+func centroid(pts [][2]float64) (float64, float64) {
+	cx, cy := 0.0, 0.0
+	for _, p := range pts {
+		cx += p[0]
+		cy += p[1]
+	}
+	n := float64(len(pts))
+	return cx / n, cy / n
+}
+
+func maxTension(fs []AnchorForce) int {
+	k := 0
+	for i, f := range fs {
+		if f.TensionKN > fs[k].TensionKN {
+			k = i
+		}
+	}
+	return k
+}
+
+// pointsLayout builds a layout from anchor coordinates with the edges at
+// the given distances beyond the outermost anchors.
+func pointsLayout(pts [][2]float64, in PlateInput) layout {
+	g := layout{pts: pts}
+	x0, x1 := math.Inf(1), math.Inf(-1)
+	y0, y1 := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		x0, x1 = math.Min(x0, p[0]), math.Max(x1, p[0])
+		y0, y1 = math.Min(y0, p[1]), math.Max(y1, p[1])
+	}
+	g.xMin = edgeAt(x0, in.EdgeLeftMM, -1)
+	g.xMax = edgeAt(x1, in.EdgeRightMM, 1)
+	g.yMin = edgeAt(y0, in.EdgeBottomMM, -1)
+	g.yMax = edgeAt(y1, in.EdgeTopMM, 1)
+	return g
+}
+
+// designStrength returns Rb for a B class or fck/1.5 otherwise.
+func designStrength(class string, fck float64) float64 {
+	c := strings.ToUpper(strings.TrimSpace(class))
+	c = strings.Replace(c, "В", "B", 1)
+	if strings.HasPrefix(c, "B") {
+		if rb, ok := rbByClass[math.Round(fck/0.8)]; ok {
+			return rb
+		}
+	}
+	return fck / 1.5
+}
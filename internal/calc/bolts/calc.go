@@ -0,0 +1,279 @@
+package bolts
+
+import (
+	"fmt"
+	"math"
+)
+
+type Mode string
+
+const (
+	ModeBearing  Mode = "bearing"
+	ModeFriction Mode = "friction"
+)
+
+type Class struct {
+	RbunMPa float64
+	RbsMPa  float64
+	RbtMPa  float64
+}
+
+// Bolt class strengths per SP16 table Г.5 (MPa).
+var Classes = map[string]Class{
+	"5.8":  {RbunMPa: 500, RbsMPa: 210, RbtMPa: 225},
+	"8.8":  {RbunMPa: 830, RbsMPa: 332, RbtMPa: 451},
+	"10.9": {RbunMPa: 1040, RbsMPa: 416, RbtMPa: 561},
+}
+
+// Net (threaded) areas Abn by nominal diameter, SP16 table Г.9 (mm2).
+var netArea = map[float64]float64{
+	12: 84.3,
+	16: 157,
+	20: 245,
+	22: 303,
+	24: 352,
+	27: 459,
+	30: 561,
+	36: 817,
+	42: 1120,
+	48: 1470,
+}
+
+// Friction coefficient μ and reliability factor γh by surface treatment,
+// SP16 table 42 (static load, tightening controlled by torque).
+var surfaces = map[string]struct{ mu, gammaH float64 }{
+	"shot":  {0.58, 1.12},
+	"flame": {0.42, 1.20},
+	"brush": {0.35, 1.35},
+	"none":  {0.25, 1.70},
+}
+
+type Bolt struct {
+	XMM float64 `json:"x_mm"`
+	YMM float64 `json:"y_mm"`
+}
+
+type Input struct {
+	Class          string  `json:"class"`
+	DiameterMM     float64 `json:"diameter_mm"`
+	Mode           Mode    `json:"mode"`
+	ShearPlanes    int     `json:"shear_planes"`
+	PlyThicknessMM float64 `json:"ply_thickness_mm"`
+	PlyRunMPa      float64 `json:"ply_run_mpa"`
+	Surface        string  `json:"surface"`
+	GammaB         float64 `json:"gamma_b"`
+	GammaC         float64 `json:"gamma_c"`
+	BoltCount      int     `json:"bolt_count"`
+	Bolts          []Bolt  `json:"bolts"`
+	ShearXKN       float64 `json:"shear_x_kn"`
+	ShearYKN       float64 `json:"shear_y_kn"`
+	LoadXMM        float64 `json:"load_x_mm"`
+	LoadYMM        float64 `json:"load_y_mm"`
+	TensionKN      float64 `json:"tension_kn"`
+}
+
+type Result struct {
+	BoltCount          int     `json:"bolt_count"`
+	ShearCapacityKN    float64 `json:"shear_capacity_kn"`
+	BearingCapacityKN  float64 `json:"bearing_capacity_kn"`
+	TensionCapacityKN  float64 `json:"tension_capacity_kn"`
+	FrictionCapacityKN float64 `json:"friction_capacity_kn,omitempty"`
+	TorsionKNm         float64 `json:"torsion_knm"`
+	CriticalBolt       int     `json:"critical_bolt"`
+	BoltShearKN        float64 `json:"bolt_shear_kn"`
+	BoltTensionKN      float64 `json:"bolt_tension_kn"`
+	ShearUtilization   float64 `json:"shear_utilization"`
+	TensionUtilization float64 `json:"tension_utilization"`
+	Utilization        float64 `json:"utilization"`
+	OK                 bool    `json:"ok"`
+	Notes              string  `json:"notes"`
+}
+
+func Calculate(in Input) (Result, error) {
+	cls, ok := Classes[in.Class]
+	if !ok {
+		return Result{}, fmt.Errorf("invalid bolt class")
+	}
+	if in.DiameterMM <= 0 {
+		return Result{}, fmt.Errorf("invalid diameter")
+	}
+	if in.Mode == "" {
+		in.Mode = ModeBearing
+	}
+	if in.Mode != ModeBearing && in.Mode != ModeFriction {
+		return Result{}, fmt.Errorf("invalid mode")
+	}
+	if in.ShearPlanes <= 0 {
+		in.ShearPlanes = 1
+	}
+	if in.PlyRunMPa <= 0 {
+		in.PlyRunMPa = 370
+	}
+	if in.GammaC <= 0 {
+		in.GammaC = 1.0
+	}
+	bolts := in.Bolts
+	if len(bolts) == 0 {
+		if in.BoltCount <= 0 {
+			return Result{}, fmt.Errorf("no bolts")
+		}
+		// No layout: all bolts at the load point share the force equally
+		bolts = make([]Bolt, in.BoltCount)
+		for i := range bolts {
+			bolts[i] = Bolt{XMM: in.LoadXMM, YMM: in.LoadYMM}
+		}
+	}
+	n := len(bolts)
+	if in.GammaB <= 0 {
+		in.GammaB = 1.0
+		if in.Mode == ModeFriction {
+			in.GammaB = frictionGammaB(n)
+		}
+	}
+
+	d := in.DiameterMM
+	ab := math.Pi * d * d / 4
+	abn := NetArea(d)
+	ns := float64(in.ShearPlanes)
+
+	nbs := cls.RbsMPa * ab * ns * in.GammaB * in.GammaC / 1000.0
+	nbt := TensionCapacity(cls, d, in.GammaC)
+	nbp := 0.0
+	if in.PlyThicknessMM > 0 {
+		nbp = Rbp(in.PlyRunMPa) * d * in.PlyThicknessMM * in.GammaB * in.GammaC / 1000.0
+	}
+
+	forces, T, err := Distribute(bolts, in.ShearXKN, in.ShearYKN, in.LoadXMM, in.LoadYMM)
+	if err != nil {
+		return Result{}, err
+	}
+	crit := 0
+	for i, f := range forces {
+		if f > forces[crit] {
+			crit = i
+		}
+	}
+	vb := forces[crit]
+	nt := in.TensionKN / float64(n)
+
+	res := Result{
+		BoltCount:         n,
+		ShearCapacityKN:   nbs,
+		BearingCapacityKN: nbp,
+		TensionCapacityKN: nbt,
+		TorsionKNm:        T,
+		CriticalBolt:      crit + 1,
+		BoltShearKN:       vb,
+		BoltTensionKN:     nt,
+	}
+	if nt > 0 {
+		res.TensionUtilization = nt / nbt
+	}
+
+	switch in.Mode {
+	case ModeFriction:
+		sf, ok := surfaces[in.Surface]
+		if !ok {
+			return Result{}, fmt.Errorf("invalid surface treatment")
+		}
+		// Preload P = Rbh*Abn with Rbh = 0.7*Rbun; tension relieves the clamp
+		p := 0.7 * cls.RbunMPa * abn / 1000.0
+		qbh := p * sf.mu / sf.gammaH
+		if nt > 0 {
+			qbh *= math.Max(1-nt/p, 0)
+		}
+		res.FrictionCapacityKN = qbh * ns * in.GammaB * in.GammaC
+		if res.FrictionCapacityKN > 0 {
+			res.ShearUtilization = vb / res.FrictionCapacityKN
+		} else if vb > 0 {
+			res.ShearUtilization = math.Inf(1)
+		}
+		res.Utilization = math.Max(res.ShearUtilization, res.TensionUtilization)
+		res.Notes = "Friction-grip connection with high-strength preloaded bolts per SP16."
+	default:
+		capKN := nbs
+		if nbp > 0 && nbp < capKN {
+			capKN = nbp
+		}
+		res.ShearUtilization = vb / capKN
+		// Combined shear and tension per SP16 14.2.11
+		combined := math.Pow(vb/nbs, 2) + math.Pow(res.TensionUtilization, 2)
+		res.Utilization = math.Max(math.Max(res.ShearUtilization, res.TensionUtilization), combined)
+		res.Notes = "Bearing-type bolted connection per SP16: shear, bearing, tension and combined checks."
+	}
+	if in.Mode == ModeFriction && in.Class == "5.8" {
+		res.Notes += " Class 5.8 bolts are not high-strength; friction capacity is indicative only."
+	}
+	res.OK = res.Utilization <= 1.0
+	return res, nil
+}
+
+// NetArea returns the threaded area of a bolt, falling back to 0.78*Ab for
+// non-standard diameters.
+func NetArea(d float64) float64 {
+	if a, ok := netArea[d]; ok {
+		return a
+	}
+	return 0.78 * math.Pi * d * d / 4
+}
+
+// TensionCapacity is the design tension resistance of one bolt (kN).
+func TensionCapacity(cls Class, d, gammaC float64) float64 {
+	return cls.RbtMPa * NetArea(d) * gammaC / 1000.0
+}
+
+// Rbp is the bearing resistance of the connected plies for accuracy class B
+// bolts, SP16 table Г.6.
+func Rbp(run float64) float64 {
+	return (0.6 + 410*run/2.06e5) * run
+}
+
+// frictionGammaB is the working factor of a friction-grip connection by the
+// number of bolts, SP16 14.3.3.
+func frictionGammaB(n int) float64 {
+	switch {
+	case n < 5:
+		return 0.8
+	case n < 10:
+		return 0.9
+	default:
+		return 1.0
+	}
+}
+
+// Distribute shares an in-plane force applied at (lx, ly) between the bolts
+// by the elastic method: direct share plus torsion proportional to the
+// distance from the group centroid. Returns the resultant force on every
+// bolt (kN) and the torsion about the centroid (kN*m).
+func Distribute(bolts []Bolt, fx, fy, lx, ly float64) ([]float64, float64, error) {
+	n := float64(len(bolts))
+	xc, yc := 0.0, 0.0
+	for _, b := range bolts {
+		xc += b.XMM
+		yc += b.YMM
+	}
+	xc /= n
+	yc /= n
+	sumR2 := 0.0
+	for _, b := range bolts {
+		sumR2 += (b.XMM-xc)*(b.XMM-xc) + (b.YMM-yc)*(b.YMM-yc)
+	}
+	T := fy*(lx-xc) - fx*(ly-yc) // kN*mm
+	if sumR2 == 0 {
+		if math.Abs(T) > 1e-9 {
+			return nil, 0, fmt.Errorf("bolt group cannot resist torsion")
+		}
+		T = 0
+	}
+	out := make([]float64, len(bolts))
+	for i, b := range bolts {
+		vx := fx / n
+		vy := fy / n
+		if sumR2 > 0 {
+			vx -= T * (b.YMM - yc) / sumR2
+			vy += T * (b.XMM - xc) / sumR2
+		}
+		out[i] = math.Hypot(vx, vy)
+	}
+	return out, T / 1000.0, nil
+}
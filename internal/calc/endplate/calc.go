@@ -0,0 +1,306 @@
+package endplate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	bolts "Vertex/internal/calc/bolts"
+	joints "Vertex/internal/calc/joints"
+)
+
+const steelEMPa = 2.06e5
+
+// Row is a pair of bolts on either side of the beam web.
+type Row struct {
+	LeverMM  float64 `json:"lever_mm"`
+	Extended bool    `json:"extended"`
+	MxMM     float64 `json:"mx_mm"`
+	ExMM     float64 `json:"ex_mm"`
+}
+
+type Input struct {
+	BeamHeightMM      float64 `json:"beam_height_mm"`
+	FlangeWidthMM     float64 `json:"flange_width_mm"`
+	FlangeThicknessMM float64 `json:"flange_thickness_mm"`
+	WebThicknessMM    float64 `json:"web_thickness_mm"`
+	BeamRyMPa         float64 `json:"beam_ry_mpa"`
+	BeamSpanM         float64 `json:"beam_span_m"`
+	Braced            bool    `json:"braced"`
+
+	PlateThicknessMM float64 `json:"plate_thickness_mm"`
+	PlateWidthMM     float64 `json:"plate_width_mm"`
+	PlateRyMPa       float64 `json:"plate_ry_mpa"`
+
+	ColumnFlangeMM float64 `json:"column_flange_mm"`
+	ColumnRyMPa    float64 `json:"column_ry_mpa"`
+
+	BoltClass      string  `json:"bolt_class"`
+	BoltDiameterMM float64 `json:"bolt_diameter_mm"`
+	GaugeMM        float64 `json:"gauge_mm"`
+	EdgeMM         float64 `json:"edge_mm"`
+	Rows           []Row   `json:"rows"`
+
+	// Weld sizes are fillet legs kf, as in joints.
+	WebWeldMM    float64        `json:"web_weld_mm"`
+	FlangeWeldMM float64        `json:"flange_weld_mm"`
+	Electrode    string         `json:"electrode"`
+	Process      joints.Process `json:"process"`
+
+	GammaC    float64 `json:"gamma_c"`
+	MomentKNm float64 `json:"moment_knm"`
+	ShearKN   float64 `json:"shear_kn"`
+}
+
+type RowResult struct {
+	LeverMM           float64 `json:"lever_mm"`
+	EffectiveLengthMM float64 `json:"effective_length_mm"`
+	Mode1KN           float64 `json:"mode1_kn"`
+	Mode2KN           float64 `json:"mode2_kn"`
+	Mode3KN           float64 `json:"mode3_kn"`
+	WebTensionKN      float64 `json:"web_tension_kn,omitempty"`
+	ResistanceKN      float64 `json:"resistance_kn"`
+	Governing         string  `json:"governing"`
+	StiffnessMM       float64 `json:"stiffness_mm"`
+}
+
+type Result struct {
+	Rows                 []RowResult `json:"rows"`
+	CompressionKN        float64     `json:"compression_kn"`
+	MomentResistanceKNm  float64     `json:"moment_resistance_knm"`
+	BeamPlasticMomentKNm float64     `json:"beam_plastic_moment_knm"`
+	StrengthClass        string      `json:"strength_class"`
+	InitialStiffnessKNm  float64     `json:"initial_stiffness_knm_rad"`
+	RigidLimitKNm        float64     `json:"rigid_limit_knm_rad"`
+	PinnedLimitKNm       float64     `json:"pinned_limit_knm_rad"`
+	StiffnessClass       string      `json:"stiffness_class"`
+	ShearUtilization     float64     `json:"shear_utilization"`
+	WeldUtilization      float64     `json:"weld_utilization,omitempty"`
+	Utilization          float64     `json:"utilization"`
+	OK                   bool        `json:"ok"`
+	Notes                string      `json:"notes"`
+}
+
+// tstub holds the geometry of one bolt row treated as an equivalent T-stub.
+type tstub struct {
+	m, e, leff1, leff2 float64
+}
+
+func Calculate(in Input) (Result, error) {
+	if in.BeamHeightMM <= 0 || in.FlangeWidthMM <= 0 || in.FlangeThicknessMM <= 0 || in.WebThicknessMM <= 0 {
+		return Result{}, fmt.Errorf("invalid beam section")
+	}
+	if in.PlateThicknessMM <= 0 || in.PlateWidthMM <= 0 {
+		return Result{}, fmt.Errorf("invalid end plate")
+	}
+	if in.BoltDiameterMM <= 0 || in.GaugeMM <= in.WebThicknessMM || len(in.Rows) == 0 {
+		return Result{}, fmt.Errorf("invalid bolt layout")
+	}
+	cls, ok := bolts.Classes[in.BoltClass]
+	if !ok {
+		return Result{}, fmt.Errorf("invalid bolt class")
+	}
+	if in.BeamRyMPa <= 0 {
+		in.BeamRyMPa = 240
+	}
+	if in.PlateRyMPa <= 0 {
+		in.PlateRyMPa = 240
+	}
+	if in.ColumnRyMPa <= 0 {
+		in.ColumnRyMPa = 240
+	}
+	if in.GammaC <= 0 {
+		in.GammaC = 1.0
+	}
+	if in.EdgeMM <= 0 {
+		in.EdgeMM = (in.PlateWidthMM - in.GaugeMM) / 2
+	}
+
+	h := in.BeamHeightMM
+	tf := in.FlangeThicknessMM
+	tw := in.WebThicknessMM
+	bf := in.FlangeWidthMM
+	tp := in.PlateThicknessMM
+	d := in.BoltDiameterMM
+	ftRd := bolts.TensionCapacity(cls, d, in.GammaC)
+
+	// Compression flange of the beam limits the sum of row forces
+	wpl := bf*tf*(h-tf) + tw*(h-2*tf)*(h-2*tf)/4
+	mpl := wpl * in.BeamRyMPa * in.GammaC / 1e6
+	fc := mpl * 1000.0 / (h - tf)
+
+	// Rows are filled from the one furthest from the compression centre
+	rows := make([]Row, len(in.Rows))
+	copy(rows, in.Rows)
+	sort.Slice(rows, func(i, j int) bool { return rows[i].LeverMM > rows[j].LeverMM })
+
+	lb := tp + in.ColumnFlangeMM + d
+	as := bolts.NetArea(d)
+	kBolt := 1.6 * as / lb
+
+	var out []RowResult
+	sumF, mj, sumKh, sumKh2 := 0.0, 0.0, 0.0, 0.0
+	for i, r := range rows {
+		if r.LeverMM <= 0 {
+			return Result{}, fmt.Errorf("invalid lever arm in row %d", i+1)
+		}
+		ts, err := rowGeometry(in, r)
+		if err != nil {
+			return Result{}, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rr := RowResult{LeverMM: r.LeverMM, EffectiveLengthMM: ts.leff2}
+		rr.Mode1KN, rr.Mode2KN, rr.Mode3KN = tstubModes(ts, tp, in.PlateRyMPa*in.GammaC, ftRd)
+		fr, gov := minMode(rr.Mode1KN, rr.Mode2KN, rr.Mode3KN)
+		k := 0.9 * ts.leff1 * tp * tp * tp / (ts.m * ts.m * ts.m)
+		kinv := 1/k + 1/kBolt
+
+		if in.ColumnFlangeMM > 0 {
+			c1, c2, c3 := tstubModes(ts, in.ColumnFlangeMM, in.ColumnRyMPa*in.GammaC, ftRd)
+			if cf, cg := minMode(c1, c2, c3); cf < fr {
+				fr, gov = cf, "column flange "+cg
+			}
+			tfc := in.ColumnFlangeMM
+			kinv += 1 / (0.9 * ts.leff1 * tfc * tfc * tfc / (ts.m * ts.m * ts.m))
+		}
+		if !r.Extended {
+			rr.WebTensionKN = ts.leff2 * tw * in.BeamRyMPa * in.GammaC / 1000.0
+			if rr.WebTensionKN < fr {
+				fr, gov = rr.WebTensionKN, "beam web in tension"
+			}
+		}
+		if sumF+fr > fc {
+			fr = math.Max(fc-sumF, 0)
+			gov = "compression flange"
+		}
+		rr.ResistanceKN = fr
+		rr.Governing = gov
+		rr.StiffnessMM = 1 / kinv
+		sumF += fr
+		mj += fr * r.LeverMM / 1000.0
+		sumKh += rr.StiffnessMM * r.LeverMM
+		sumKh2 += rr.StiffnessMM * r.LeverMM * r.LeverMM
+		out = append(out, rr)
+	}
+
+	// Equivalent lever arm and stiffness of the tension zone
+	zeq := sumKh2 / sumKh
+	keq := sumKh / zeq
+	sj := steelEMPa * zeq * zeq * keq / 1e6 // kN*m/rad
+
+	ib := bf*tf*tf*tf/6 + bf*tf*(h-tf)*(h-tf)/2 + tw*math.Pow(h-2*tf, 3)/12
+	res := Result{
+		Rows:                 out,
+		CompressionKN:        fc,
+		MomentResistanceKNm:  mj,
+		BeamPlasticMomentKNm: mpl,
+		InitialStiffnessKNm:  sj,
+		StrengthClass:        "partial-strength",
+		StiffnessClass:       "semi-rigid",
+	}
+	if mj >= mpl {
+		res.StrengthClass = "full-strength"
+	}
+	if in.BeamSpanM > 0 {
+		kb := 25.0
+		if in.Braced {
+			kb = 8.0
+		}
+		eib := steelEMPa * ib / (in.BeamSpanM * 1000.0) / 1e6
+		res.RigidLimitKNm = kb * eib
+		res.PinnedLimitKNm = 0.5 * eib
+		switch {
+		case sj >= res.RigidLimitKNm:
+			res.StiffnessClass = "rigid"
+		case sj <= res.PinnedLimitKNm:
+			res.StiffnessClass = "pinned"
+		}
+	}
+	if mj <= 0 {
+		return Result{}, fmt.Errorf("connection has no moment resistance")
+	}
+	res.Utilization = math.Abs(in.MomentKNm) / mj
+
+	if in.ShearKN != 0 {
+		br, err := bolts.Calculate(bolts.Input{
+			Class:          in.BoltClass,
+			DiameterMM:     d,
+			BoltCount:      2 * len(rows),
+			PlyThicknessMM: tp,
+			GammaC:         in.GammaC,
+			ShearYKN:       in.ShearKN,
+		})
+		if err != nil {
+			return Result{}, err
+		}
+		res.ShearUtilization = br.ShearUtilization
+		res.Utilization = math.Max(res.Utilization, res.ShearUtilization)
+	}
+
+	if in.FlangeWeldMM > 0 && in.MomentKNm != 0 {
+		wr, err := joints.Calculate(joints.Input{
+			WeldSizeMM:   in.FlangeWeldMM,
+			WeldLengthMM: 2*bf - tw,
+			Electrode:    in.Electrode,
+			Process:      in.Process,
+			GammaC:       in.GammaC,
+			ShearKN:      math.Abs(in.MomentKNm) * 1000.0 / (h - tf),
+		})
+		if err != nil {
+			return Result{}, err
+		}
+		res.WeldUtilization = wr.Utilization
+		res.Utilization = math.Max(res.Utilization, wr.Utilization)
+	}
+
+	res.OK = res.Utilization <= 1.0
+	res.Notes = "Extended end plate by the equivalent T-stub method. Stiffness excludes the column web panel; bolt shear assumes all rows share the shear force."
+	return res, nil
+}
+
+// rowGeometry returns the T-stub lengths for a row. Extended rows use the
+// plate overhang beyond the tension flange, inner rows the web side.
+func rowGeometry(in Input, r Row) (tstub, error) {
+	w := in.GaugeMM
+	e := in.EdgeMM
+	if r.Extended {
+		mx := r.MxMM - 0.8*in.FlangeWeldMM
+		ex := r.ExMM
+		if mx <= 0 || ex <= 0 {
+			return tstub{}, fmt.Errorf("invalid extension geometry")
+		}
+		lcp := math.Min(2*math.Pi*mx, math.Min(math.Pi*mx+w, math.Pi*mx+2*e))
+		lnc := math.Min(math.Min(4*mx+1.25*ex, e+2*mx+0.625*ex), math.Min(0.5*in.PlateWidthMM, 0.5*w+2*mx+0.625*ex))
+		return tstub{m: mx, e: ex, leff1: math.Min(lcp, lnc), leff2: lnc}, nil
+	}
+	m := (w-in.WebThicknessMM)/2 - 0.8*in.WebWeldMM
+	if m <= 0 {
+		return tstub{}, fmt.Errorf("bolts too close to the web")
+	}
+	lcp := 2 * math.Pi * m
+	lnc := 4*m + 1.25*e
+	return tstub{m: m, e: e, leff1: math.Min(lcp, lnc), leff2: lnc}, nil
+}
+
+// tstubModes returns the row resistance (kN) for plate yielding (mode 1),
+// yielding with bolt failure (mode 2) and bolt failure (mode 3).
+func tstubModes(ts tstub, t, fy, ftRd float64) (f1, f2, f3 float64) {
+	mpl1 := 0.25 * ts.leff1 * t * t * fy
+	mpl2 := 0.25 * ts.leff2 * t * t * fy
+	n := math.Min(ts.e, 1.25*ts.m)
+	sumFt := 2 * ftRd * 1000.0
+	f1 = 4 * mpl1 / ts.m / 1000.0
+	f2 = (2*mpl2 + n*sumFt) / (ts.m + n) / 1000.0
+	f3 = sumFt / 1000.0
+	return f1, f2, f3
+}
+
+func minMode(f1, f2, f3 float64) (float64, string) {
+	f, gov := f1, "mode 1"
+	if f2 < f {
+		f, gov = f2, "mode 2"
+	}
+	if f3 < f {
+		f, gov = f3, "mode 3"
+	}
+	return f, gov
+}
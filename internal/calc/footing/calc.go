@@ -0,0 +1,308 @@
+package footing
+
+import (
+	"fmt"
+	"math"
+
+	slab "Vertex/internal/calc/SP/slab-SP"
+	settlement "Vertex/internal/calc/settlement"
+)
+
+// Layer is a soil layer by depth from the ground (m) with the values of
+// the second limit state.
+type Layer struct {
+	FromDepthM float64 `json:"from_depth_m"`
+	ToDepthM   float64 `json:"to_depth_m"`
+	GammaKNM3  float64 `json:"gamma_kn_m3"`
+	PhiDeg     float64 `json:"phi_deg"`
+	CKPa       float64 `json:"c_kpa"`
+	EMPa       float64 `json:"e_mpa"`
+}
+
+// Input is a pad or strip footing under N and M at its top, strip loads
+// per metre. The moment acts along L of a pad and across a strip. Without
+// BM the smallest B on the 0.3 m module that passes is chosen, with
+// L = LRatio·B for pads. K is 1 for tested soil strength and 1.1 for
+// tabulated. The loads are those of the second limit state; the slab is
+// reinforced for them times GammaF.
+type Input struct {
+	Type              string  `json:"type"`
+	NKN               float64 `json:"n_kn"`
+	MKNm              float64 `json:"m_knm"`
+	DepthM            float64 `json:"depth_m"`
+	BasementDepthM    float64 `json:"basement_depth_m"`
+	BM                float64 `json:"b_m"`
+	LM                float64 `json:"l_m"`
+	LRatio            float64 `json:"l_ratio"`
+	GammaC1           float64 `json:"gamma_c1"`
+	GammaC2           float64 `json:"gamma_c2"`
+	K                 float64 `json:"k"`
+	GammaMeanKNM3     float64 `json:"gamma_mean_kn_m3"`
+	Layers            []Layer `json:"layers"`
+	SettlementLimitMM float64 `json:"settlement_limit_mm"`
+	HeightM           float64 `json:"height_m"`
+	ColumnBM          float64 `json:"column_b_m"`
+	ColumnLM          float64 `json:"column_l_m"`
+	CoverMM           float64 `json:"cover_mm"`
+	RbMPa             float64 `json:"rb_mpa"`
+	RsMPa             float64 `json:"rs_mpa"`
+	BarDiameterMM     float64 `json:"bar_diameter_mm"`
+	GammaF            float64 `json:"gamma_f"`
+}
+
+type Result struct {
+	BM                 float64               `json:"b_m"`
+	LM                 float64               `json:"l_m"`
+	AreaM2             float64               `json:"area_m2"`
+	MGamma             float64               `json:"m_gamma"`
+	Mq                 float64               `json:"mq"`
+	Mc                 float64               `json:"mc"`
+	Kz                 float64               `json:"kz"`
+	GammaIIKNM3        float64               `json:"gamma_ii_kn_m3"`
+	GammaIIAboveKNM3   float64               `json:"gamma_ii_above_kn_m3"`
+	RKPa               float64               `json:"r_kpa"`
+	PressureKPa        float64               `json:"pressure_kpa"`
+	PMaxKPa            float64               `json:"p_max_kpa"`
+	PMinKPa            float64               `json:"p_min_kpa"`
+	EccentricityM      float64               `json:"eccentricity_m"`
+	PressureOK         bool                  `json:"pressure_ok"`
+	EdgeOK             bool                  `json:"edge_ok"`
+	SettlementMM       float64               `json:"settlement_mm"`
+	CompressibleDepthM float64               `json:"compressible_depth_m,omitempty"`
+	SettlementLimitMM  float64               `json:"settlement_limit_mm"`
+	SettlementOK       bool                  `json:"settlement_ok"`
+	Sublayers          []settlement.Sublayer `json:"sublayers,omitempty"`
+	ReinforcementB     *slab.Result          `json:"reinforcement_b,omitempty"`
+	ReinforcementL     *slab.Result          `json:"reinforcement_l,omitempty"`
+	OK                 bool                  `json:"ok"`
+	Notes              string                `json:"notes"`
+}
+
+const (
+	sizeModule = 0.3
+	maxAutoB   = 12.0
+	// A strip is settled as a rectangle of this L/B
+	stripRatio = 10.0
+)
+
+// bearingFactors are Mγ, Mq and Mc of SP22 table 5.5, from the closed form
+// the table is built on.
+func bearingFactors(phiDeg float64) (mg, mq, mc float64) {
+	if phiDeg <= 0 {
+		return 0, 1, math.Pi
+	}
+	phi := phiDeg * math.Pi / 180
+	cot := 1 / math.Tan(phi)
+	d := cot + phi - math.Pi/2
+	return math.Pi / 4 / d, 1 + math.Pi/d, math.Pi * cot / d
+}
+
+func layerAt(layers []Layer, z float64) (Layer, bool) {
+	for _, l := range layers {
+		if z >= l.FromDepthM-1e-9 && z < l.ToDepthM-1e-9 {
+			return l, true
+		}
+	}
+	return Layer{}, false
+}
+
+func Calculate(in Input) (Result, error) {
+	if in.Type == "" {
+		in.Type = "pad"
+	}
+	if in.Type != "pad" && in.Type != "strip" {
+		return Result{}, fmt.Errorf("invalid footing type")
+	}
+	if in.NKN <= 0 {
+		return Result{}, fmt.Errorf("invalid axial load")
+	}
+	if in.DepthM <= 0 {
+		return Result{}, fmt.Errorf("invalid depth")
+	}
+	if len(in.Layers) == 0 {
+		return Result{}, fmt.Errorf("no layers provided")
+	}
+	if in.LRatio <= 0 {
+		in.LRatio = 1
+	}
+	if in.GammaC1 <= 0 {
+		in.GammaC1 = 1
+	}
+	if in.GammaC2 <= 0 {
+		in.GammaC2 = 1
+	}
+	if in.K <= 0 {
+		in.K = 1
+	}
+	if in.GammaMeanKNM3 <= 0 {
+		in.GammaMeanKNM3 = 20
+	}
+	if in.SettlementLimitMM <= 0 {
+		in.SettlementLimitMM = 100
+	}
+
+	var res Result
+	base, ok := layerAt(in.Layers, in.DepthM)
+	if !ok {
+		return Result{}, fmt.Errorf("no layer under the footing")
+	}
+	res.GammaIIKNM3 = base.GammaKNM3
+	for _, l := range in.Layers {
+		res.GammaIIAboveKNM3 += l.GammaKNM3 * math.Max(0, math.Min(in.DepthM, l.ToDepthM)-l.FromDepthM) / in.DepthM
+	}
+	res.MGamma, res.Mq, res.Mc = bearingFactors(base.PhiDeg)
+
+	// Settlement needs a modulus in every layer
+	stiff := true
+	var soil []settlement.Layer
+	for _, l := range in.Layers {
+		stiff = stiff && l.EMPa > 0
+		soil = append(soil, settlement.Layer{TopM: l.FromDepthM, BottomM: l.ToDepthM, GammaKNm3: l.GammaKNM3, EMPa: l.EMPa})
+	}
+
+	check := func(b, l float64) (Result, error) {
+		r := res
+		r.BM, r.LM = b, l
+		// Design soil resistance, SP22 (5.7)
+		r.Kz = 1
+		if b >= 10 {
+			r.Kz = 8/b + 0.2
+		}
+		db := math.Min(in.BasementDepthM, 2)
+		d1 := in.DepthM - in.BasementDepthM
+		r.RKPa = in.GammaC1 * in.GammaC2 / in.K * (r.MGamma*r.Kz*b*r.GammaIIKNM3 + r.Mq*d1*r.GammaIIAboveKNM3 +
+			(r.Mq-1)*db*r.GammaIIAboveKNM3 + r.Mc*base.CKPa)
+
+		// Edge pressure with the weight of the footing and backfill
+		area, w := b*l, b*l*l/6
+		if in.Type == "strip" {
+			area, w = b, b*b/6
+		}
+		r.AreaM2 = area
+		n := in.NKN + in.GammaMeanKNM3*in.DepthM*area
+		r.PressureKPa = n / area
+		r.EccentricityM = math.Abs(in.MKNm) / n
+		r.PMaxKPa = r.PressureKPa + math.Abs(in.MKNm)/w
+		r.PMinKPa = r.PressureKPa - math.Abs(in.MKNm)/w
+		r.PressureOK = r.PressureKPa <= r.RKPa
+		r.EdgeOK = r.PMaxKPa <= 1.2*r.RKPa && r.PMinKPa >= 0
+		r.OK = r.PressureOK && r.EdgeOK
+
+		if stiff {
+			sl := l
+			if in.Type == "strip" {
+				sl = stripRatio * b
+			}
+			s, err := settlement.Calculate(soil, settlement.Footing{BM: b, LM: sl, DepthM: in.DepthM, PressureKPa: r.PressureKPa}, 0.5)
+			if err != nil {
+				return Result{}, err
+			}
+			r.SettlementMM = s.SettlementM * 1000
+			r.CompressibleDepthM = s.CompressibleDepthM
+			r.Sublayers = s.Sublayers
+			r.SettlementLimitMM = in.SettlementLimitMM
+			r.SettlementOK = r.SettlementMM <= in.SettlementLimitMM
+			r.OK = r.OK && r.SettlementOK
+		}
+		return r, nil
+	}
+
+	if in.BM > 0 {
+		l := in.LM
+		if l <= 0 {
+			l = in.BM * in.LRatio
+		}
+		var err error
+		if res, err = check(in.BM, l); err != nil {
+			return Result{}, err
+		}
+	} else {
+		found := false
+		for i := 2; float64(i)*sizeModule <= maxAutoB+1e-9; i++ {
+			b := float64(i) * sizeModule
+			l := math.Ceil(b*in.LRatio/sizeModule-1e-9) * sizeModule
+			r, err := check(b, l)
+			if err != nil {
+				return Result{}, err
+			}
+			if r.OK {
+				res, found = r, true
+				break
+			}
+		}
+		if !found {
+			return Result{}, fmt.Errorf("no footing size up to %.0f m", maxAutoB)
+		}
+	}
+	if in.Type == "strip" {
+		res.LM = 1
+	}
+
+	if err := reinforce(in, &res); err != nil {
+		return Result{}, err
+	}
+	res.Notes = "SP22 (5.7) with Mγ, Mq, Mc of table 5.5; pmax ≤ 1.2R, pmin ≥ 0; layer summation to σzp = 0.5σzg; slab per SP63 strip."
+	return res, nil
+}
+
+// reinforce designs the slab as cantilevers from the column or wall faces
+// under the net edge pressure, per metre of width.
+func reinforce(in Input, res *Result) error {
+	if in.HeightM <= 0 {
+		in.HeightM = 0.5
+	}
+	if in.ColumnBM <= 0 {
+		in.ColumnBM = 0.4
+	}
+	if in.ColumnLM <= 0 {
+		in.ColumnLM = in.ColumnBM
+	}
+	if in.CoverMM <= 0 {
+		in.CoverMM = 50
+	}
+	if in.RbMPa <= 0 {
+		in.RbMPa = 11.5
+	}
+	if in.RsMPa <= 0 {
+		in.RsMPa = 350
+	}
+	if in.BarDiameterMM <= 0 {
+		in.BarDiameterMM = 12
+	}
+	if in.GammaF <= 0 {
+		in.GammaF = 1.15
+	}
+	h0 := in.HeightM*1000 - in.CoverMM - in.BarDiameterMM/2
+	if h0 <= 0 {
+		return fmt.Errorf("invalid footing height")
+	}
+	design := func(c, p float64) (*slab.Result, error) {
+		if c <= 0 || p <= 0 {
+			return nil, nil
+		}
+		r, err := slab.Calculate(slab.Input{
+			MomentKNmPerM:    in.GammaF * p * c * c / 2,
+			EffectiveDepthMM: h0,
+			RbMPa:            in.RbMPa,
+			RsMPa:            in.RsMPa,
+			BarDiameterMM:    in.BarDiameterMM,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &r, nil
+	}
+	// The moment only raises the pressure in its own direction
+	net := in.NKN / res.AreaM2
+	edge := res.PMaxKPa - (res.PressureKPa - net)
+	var err error
+	if in.Type == "strip" {
+		res.ReinforcementB, err = design((res.BM-in.ColumnBM)/2, edge)
+		return err
+	}
+	if res.ReinforcementB, err = design((res.BM-in.ColumnBM)/2, net); err != nil {
+		return err
+	}
+	res.ReinforcementL, err = design((res.LM-in.ColumnLM)/2, edge)
+	return err
+}
@@ -0,0 +1,355 @@
+package joints
+
+import (
+	"fmt"
+	"math"
+)
+
+type GroupMethod string
+
+const (
+	GroupElastic GroupMethod = "elastic"
+	GroupICR     GroupMethod = "icr"
+)
+
+// Segment is a straight fillet weld line in the plane of the group (mm).
+type Segment struct {
+	X1MM  float64 `json:"x1_mm"`
+	Y1MM  float64 `json:"y1_mm"`
+	X2MM  float64 `json:"x2_mm"`
+	Y2MM  float64 `json:"y2_mm"`
+	LegMM float64 `json:"leg_mm"`
+}
+
+type GroupInput struct {
+	Method     GroupMethod `json:"method"`
+	Segments   []Segment   `json:"segments"`
+	WeldSizeMM float64     `json:"weld_size_mm"`
+	Electrode  string      `json:"electrode"`
+	Process    Process     `json:"process"`
+	RunMPa     float64     `json:"run_mpa"`
+	GammaWf    float64     `json:"gamma_wf"`
+	GammaWz    float64     `json:"gamma_wz"`
+	GammaC     float64     `json:"gamma_c"`
+	FxKN       float64     `json:"fx_kn"`
+	FyKN       float64     `json:"fy_kn"`
+	LoadXMM    float64     `json:"load_x_mm"`
+	LoadYMM    float64     `json:"load_y_mm"`
+	NormalKN   float64     `json:"normal_kn"`
+	MxKNm      float64     `json:"mx_knm"`
+	MyKNm      float64     `json:"my_knm"`
+}
+
+type CriticalPoint struct {
+	Segment   int     `json:"segment"`
+	XMM       float64 `json:"x_mm"`
+	YMM       float64 `json:"y_mm"`
+	TauXMPa   float64 `json:"tau_x_mpa"`
+	TauYMPa   float64 `json:"tau_y_mpa"`
+	SigmaMPa  float64 `json:"sigma_mpa"`
+	StressMPa float64 `json:"stress_mpa"`
+}
+
+type GroupResult struct {
+	ThroatAreaMM2   float64       `json:"throat_area_mm2"`
+	CentroidXMM     float64       `json:"centroid_x_mm"`
+	CentroidYMM     float64       `json:"centroid_y_mm"`
+	IxMM4           float64       `json:"ix_mm4"`
+	IyMM4           float64       `json:"iy_mm4"`
+	JMM4            float64       `json:"j_mm4"`
+	TorsionKNm      float64       `json:"torsion_knm"`
+	Critical        CriticalPoint `json:"critical"`
+	DesignStressMPa float64       `json:"design_stress_mpa"`
+	ICRCapacityKN   float64       `json:"icr_capacity_kn,omitempty"`
+	ICRXMM          float64       `json:"icr_x_mm,omitempty"`
+	ICRYMM          float64       `json:"icr_y_mm,omitempty"`
+	Utilization     float64       `json:"utilization"`
+	OK              bool          `json:"ok"`
+	Notes           string        `json:"notes"`
+}
+
+type groupProps struct {
+	area, xc, yc, ix, iy float64
+}
+
+func CalculateGroup(in GroupInput) (GroupResult, error) {
+	if len(in.Segments) == 0 {
+		return GroupResult{}, fmt.Errorf("no weld segments")
+	}
+	wd, err := newSP16Weld(in.Electrode, in.RunMPa, in.GammaWf, in.GammaWz, in.GammaC)
+	if err != nil {
+		return GroupResult{}, err
+	}
+	if in.Method == "" {
+		in.Method = GroupElastic
+	}
+	if in.Method != GroupElastic && in.Method != GroupICR {
+		return GroupResult{}, fmt.Errorf("invalid method")
+	}
+	// Each segment gets an equivalent throat referred to the weld metal
+	// resistance, so the weaker of the metal and fusion sections governs.
+	design := wd.metal(1)
+	throats := make([]float64, len(in.Segments))
+	for i := range in.Segments {
+		if in.Segments[i].LegMM <= 0 {
+			in.Segments[i].LegMM = in.WeldSizeMM
+		}
+		if in.Segments[i].LegMM <= 0 || segLength(in.Segments[i]) <= 0 {
+			return GroupResult{}, fmt.Errorf("invalid segment %d", i+1)
+		}
+		bf, bz, err := weldBeta(in.Process, in.Segments[i].LegMM)
+		if err != nil {
+			return GroupResult{}, err
+		}
+		throats[i] = in.Segments[i].LegMM * math.Min(wd.metal(bf), wd.fusion(bz)) / design
+	}
+
+	p := properties(in.Segments, throats)
+	J := p.ix + p.iy
+	if J <= 0 {
+		return GroupResult{}, fmt.Errorf("degenerate weld group")
+	}
+	if (in.MxKNm != 0 && p.ix <= 0) || (in.MyKNm != 0 && p.iy <= 0) {
+		return GroupResult{}, fmt.Errorf("weld group cannot resist out-of-plane moment")
+	}
+
+	// Torsion about the centroid from the eccentric in-plane force, N*mm
+	T := (in.FyKN*(in.LoadXMM-p.xc) - in.FxKN*(in.LoadYMM-p.yc)) * 1000.0
+	Mx := in.MxKNm * 1e6
+	My := in.MyKNm * 1e6
+
+	var crit CriticalPoint
+	for i, s := range in.Segments {
+		for _, pt := range [][2]float64{{s.X1MM, s.Y1MM}, {s.X2MM, s.Y2MM}} {
+			dx := pt[0] - p.xc
+			dy := pt[1] - p.yc
+			tx := in.FxKN*1000.0/p.area - T*dy/J
+			ty := in.FyKN*1000.0/p.area + T*dx/J
+			sg := in.NormalKN * 1000.0 / p.area
+			if Mx != 0 {
+				sg += Mx * dy / p.ix
+			}
+			if My != 0 {
+				sg += My * dx / p.iy
+			}
+			st := math.Sqrt(tx*tx + ty*ty + sg*sg)
+			if st > crit.StressMPa || crit.Segment == 0 {
+				crit = CriticalPoint{
+					Segment:   i + 1,
+					XMM:       pt[0],
+					YMM:       pt[1],
+					TauXMPa:   tx,
+					TauYMPa:   ty,
+					SigmaMPa:  sg,
+					StressMPa: st,
+				}
+			}
+		}
+	}
+
+	res := GroupResult{
+		ThroatAreaMM2:   p.area,
+		CentroidXMM:     p.xc,
+		CentroidYMM:     p.yc,
+		IxMM4:           p.ix,
+		IyMM4:           p.iy,
+		JMM4:            J,
+		TorsionKNm:      T / 1e6,
+		Critical:        crit,
+		DesignStressMPa: design,
+		Utilization:     crit.StressMPa / design,
+		Notes:           "Elastic polar-moment method; stresses on the equivalent throat combined vectorially at segment ends.",
+	}
+
+	if in.Method == GroupICR {
+		if in.NormalKN != 0 || in.MxKNm != 0 || in.MyKNm != 0 {
+			return GroupResult{}, fmt.Errorf("icr method supports in-plane loads only")
+		}
+		P := math.Hypot(in.FxKN, in.FyKN)
+		if P <= 0 {
+			return GroupResult{}, fmt.Errorf("no in-plane force")
+		}
+		capKN, cx, cy, err := icrCapacity(in, throats, p, design)
+		if err != nil {
+			return GroupResult{}, err
+		}
+		res.ICRCapacityKN = capKN
+		res.ICRXMM = cx
+		res.ICRYMM = cy
+		res.Utilization = P / capKN
+		res.Notes = "Instantaneous center of rotation method with fillet weld load-deformation curves; elastic stresses given for reference."
+	}
+	res.OK = res.Utilization <= 1.0
+	return res, nil
+}
+
+func segLength(s Segment) float64 {
+	return math.Hypot(s.X2MM-s.X1MM, s.Y2MM-s.Y1MM)
+}
+
+func properties(segs []Segment, throats []float64) groupProps {
+	var p groupProps
+	for i, s := range segs {
+		a := throats[i] * segLength(s)
+		p.area += a
+		p.xc += a * (s.X1MM + s.X2MM) / 2
+		p.yc += a * (s.Y1MM + s.Y2MM) / 2
+	}
+	p.xc /= p.area
+	p.yc /= p.area
+	for i, s := range segs {
+		a := throats[i] * segLength(s)
+		dx := s.X2MM - s.X1MM
+		dy := s.Y2MM - s.Y1MM
+		mx := (s.X1MM+s.X2MM)/2 - p.xc
+		my := (s.Y1MM+s.Y2MM)/2 - p.yc
+		p.ix += a*dy*dy/12 + a*my*my
+		p.iy += a*dx*dx/12 + a*mx*mx
+	}
+	return p
+}
+
+type weldElem struct {
+	x, y   float64 // element centre, mm
+	ux, uy float64 // unit vector along the weld
+	length float64
+	throat float64
+	leg    float64
+}
+
+const icrElemsPerSegment = 20
+
+func discretize(segs []Segment, throats []float64) []weldElem {
+	var out []weldElem
+	for i, s := range segs {
+		L := segLength(s)
+		ux := (s.X2MM - s.X1MM) / L
+		uy := (s.Y2MM - s.Y1MM) / L
+		dl := L / icrElemsPerSegment
+		for k := 0; k < icrElemsPerSegment; k++ {
+			t := (float64(k) + 0.5) * dl
+			out = append(out, weldElem{
+				x:      s.X1MM + ux*t,
+				y:      s.Y1MM + uy*t,
+				ux:     ux,
+				uy:     uy,
+				length: dl,
+				throat: throats[i],
+				leg:    s.LegMM,
+			})
+		}
+	}
+	return out
+}
+
+// icrResidual returns the force equilibrium residual (kN) and the ultimate
+// load for an assumed centre of rotation (cx, cy).
+func icrResidual(elems []weldElem, cx, cy, qx, qy, dx, dy, design float64) (rx, ry, P float64, ok bool) {
+	arm := (qx-cx)*dy - (qy-cy)*dx
+	if math.Abs(arm) < 1e-9 {
+		return 0, 0, 0, false
+	}
+	sense := math.Copysign(1, arm)
+
+	type state struct {
+		r, fx, fy, du, dm, rn float64
+	}
+	st := make([]state, len(elems))
+	ratio := math.Inf(1)
+	for i, e := range elems {
+		rxv := e.x - cx
+		ryv := e.y - cy
+		r := math.Hypot(rxv, ryv)
+		if r < 1e-9 {
+			continue
+		}
+		// Resisting force acts perpendicular to the radius, opposing rotation
+		fx := sense * ryv / r
+		fy := -sense * rxv / r
+		sinT := math.Abs(fx*e.uy - fy*e.ux)
+		theta := math.Asin(math.Min(sinT, 1)) * 180 / math.Pi
+		du := math.Min(1.087*math.Pow(theta+6, -0.65)*e.leg, 0.17*e.leg)
+		dm := 0.209 * math.Pow(theta+2, -0.32) * e.leg
+		rn := design * e.throat * e.length * (1 + 0.5*math.Pow(sinT, 1.5)) / 1000.0
+		st[i] = state{r: r, fx: fx, fy: fy, du: du, dm: dm, rn: rn}
+		if du/r < ratio {
+			ratio = du / r
+		}
+	}
+
+	var sumFx, sumFy, sumM float64
+	for _, s := range st {
+		if s.r == 0 {
+			continue
+		}
+		pr := ratio * s.r / s.dm
+		f := math.Pow(math.Max(pr*(1.9-0.9*pr), 0), 0.3)
+		R := s.rn * f
+		sumFx += R * s.fx
+		sumFy += R * s.fy
+		sumM += R * s.r
+	}
+	P = sumM / math.Abs(arm)
+	return sumFx + P*dx, sumFy + P*dy, P, true
+}
+
+func icrCapacity(in GroupInput, throats []float64, p groupProps, design float64) (float64, float64, float64, error) {
+	elems := discretize(in.Segments, throats)
+	Pm := math.Hypot(in.FxKN, in.FyKN)
+	dx := in.FxKN / Pm
+	dy := in.FyKN / Pm
+	e := (in.LoadXMM-p.xc)*dy - (in.LoadYMM-p.yc)*dx
+
+	// Concentric load: every element reaches its strength at the load angle
+	if math.Abs(e) < 1e-6 {
+		total := 0.0
+		for _, el := range elems {
+			sinT := math.Abs(dx*el.uy - dy*el.ux)
+			total += design * el.throat * el.length * (1 + 0.5*math.Pow(sinT, 1.5)) / 1000.0
+		}
+		return total, 0, 0, nil
+	}
+
+	// Start from the elastic centre of rotation, J/(A*e) behind the centroid
+	J := p.ix + p.iy
+	r0 := J / (p.area * math.Abs(e))
+	nx, ny := dy, -dx
+	if e < 0 {
+		nx, ny = -nx, -ny
+	}
+	cx := p.xc - nx*r0
+	cy := p.yc - ny*r0
+
+	h := 1e-3 * math.Sqrt(J/p.area)
+	for iter := 0; iter < 100; iter++ {
+		rx, ry, P, ok := icrResidual(elems, cx, cy, in.LoadXMM, in.LoadYMM, dx, dy, design)
+		if !ok {
+			return 0, 0, 0, fmt.Errorf("icr iteration failed")
+		}
+		if math.Hypot(rx, ry) < 1e-6*P {
+			return P, cx, cy, nil
+		}
+		rx1, ry1, _, ok1 := icrResidual(elems, cx+h, cy, in.LoadXMM, in.LoadYMM, dx, dy, design)
+		rx2, ry2, _, ok2 := icrResidual(elems, cx, cy+h, in.LoadXMM, in.LoadYMM, dx, dy, design)
+		if !ok1 || !ok2 {
+			return 0, 0, 0, fmt.Errorf("icr iteration failed")
+		}
+		a11, a12 := (rx1-rx)/h, (rx2-rx)/h
+		a21, a22 := (ry1-ry)/h, (ry2-ry)/h
+		det := a11*a22 - a12*a21
+		if math.Abs(det) < 1e-15 {
+			return 0, 0, 0, fmt.Errorf("icr iteration failed")
+		}
+		sx := (rx*a22 - ry*a12) / det
+		sy := (a11*ry - a21*rx) / det
+		// Limit the step to keep the centre from jumping across the group
+		step := math.Hypot(sx, sy)
+		if lim := 0.5 * math.Hypot(cx-p.xc, cy-p.yc); step > lim && lim > 0 {
+			sx *= lim / step
+			sy *= lim / step
+		}
+		cx -= sx
+		cy -= sy
+	}
+	return 0, 0, 0, fmt.Errorf("icr did not converge")
+}
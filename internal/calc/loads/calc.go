@@ -8,32 +8,132 @@ const (
 	MethodSP24 Method = "SP24"
 	MethodSP22 Method = "SP22"
 	MethodEC7  Method = "EC7"
+	// MethodEN1990 combines the cases per EN 1990 instead of SP20
+	MethodEN1990 Method = "EN1990"
 )
 
+// Input takes either named load cases for the SP20 combination engine or,
+// when Cases is empty, one permanent and two variable loads combined with
+// the fixed factors of Method. Live adds floor loads from the occupancy
+// library as cases; without Cases the bare loads then become cases too.
 type Input struct {
-	Method      Method  `json:"method"`
-	LoadGKN     float64 `json:"load_g_kn"`
-	LoadQLongKN float64 `json:"load_q_long_kn"`
-	LoadQShortKN float64 `json:"load_q_short_kn"`
+	Method       Method     `json:"method"`
+	LoadGKN      float64    `json:"load_g_kn"`
+	LoadQLongKN  float64    `json:"load_q_long_kn"`
+	LoadQShortKN float64    `json:"load_q_short_kn"`
+	Cases        []LoadCase `json:"cases"`
+	Live         []LiveLoad `json:"live"`
 }
 
 type Result struct {
-	DesignLoadKN float64 `json:"design_load_kn"`
-	ComboName    string  `json:"combo_name"`
-	Notes        string  `json:"notes"`
+	DesignLoadKN    float64       `json:"design_load_kn"`
+	MinDesignLoadKN float64       `json:"min_design_load_kn"`
+	ComboName       string        `json:"combo_name"`
+	Formula         string        `json:"formula"`
+	Governing       []Combination `json:"governing"`
+	Combinations    []Combination `json:"combinations"`
+	Serviceability  []Combination `json:"serviceability,omitempty"`
+	LiveLoads       []LiveResult  `json:"live_loads,omitempty"`
+	Notes           string        `json:"notes"`
 }
 
 func Calculate(in Input) (Result, error) {
-	if in.LoadGKN <= 0 {
-		return Result{}, fmt.Errorf("invalid permanent load")
-	}
-	gG, gQlong, gQshort, name := factors(in.Method)
-	design := in.LoadGKN*gG + in.LoadQLongKN*gQlong + in.LoadQShortKN*gQshort
-	return Result{
-		DesignLoadKN: design,
-		ComboName:    name,
-		Notes:        "Simplified combination with one permanent and two variable loads.",
-	}, nil
+	var live []LiveResult
+	if len(in.Live) > 0 {
+		lc, lr, err := LiveCases(in.Live)
+		if err != nil {
+			return Result{}, err
+		}
+		if in.Method == MethodEN1990 {
+			lc = enLiveCases(lr)
+		}
+		if len(in.Cases) == 0 {
+			in.Cases = bareCases(in)
+		}
+		in.Cases = append(in.Cases, lc...)
+		live = lr
+	}
+	if in.Method == MethodEN1990 {
+		if len(in.Cases) == 0 {
+			in.Cases = bareCases(in)
+		}
+		return calculateEN1990(in.Cases, live)
+	}
+	if len(in.Cases) == 0 {
+		if in.LoadGKN <= 0 {
+			return Result{}, fmt.Errorf("invalid permanent load")
+		}
+		gG, gQlong, gQshort, name := factors(in.Method)
+		design := in.LoadGKN*gG + in.LoadQLongKN*gQlong + in.LoadQShortKN*gQshort
+		return Result{
+			DesignLoadKN:    design,
+			MinDesignLoadKN: design,
+			ComboName:       name,
+			Formula:         fmt.Sprintf("%s·G + %s·Ql + %s·Qt", num(gG), num(gQlong), num(gQshort)),
+			Notes:           "Simplified combination with one permanent and two variable loads.",
+		}, nil
+	}
+	combos, err := Combine(in.Cases)
+	if err != nil {
+		return Result{}, err
+	}
+	gov := Governing(combos)
+	res := Result{
+		DesignLoadKN:    gov[0].ValueKN,
+		MinDesignLoadKN: gov[len(gov)-1].ValueKN,
+		ComboName:       gov[0].Name,
+		Formula:         gov[0].Formula,
+		Governing:       gov,
+		Combinations:    combos,
+		LiveLoads:       live,
+		Notes:           "Basic and special combinations per SP20 section 6.",
+	}
+	return res, nil
+}
+
+func calculateEN1990(cases []LoadCase, live []LiveResult) (Result, error) {
+	combos, err := CombineEN1990(cases)
+	if err != nil {
+		return Result{}, err
+	}
+	uls, sls := splitKinds(combos)
+	gov := Governing(uls)
+	if len(gov) == 0 {
+		return Result{}, fmt.Errorf("no ultimate combinations")
+	}
+	res := Result{
+		DesignLoadKN:    gov[0].ValueKN,
+		MinDesignLoadKN: gov[len(gov)-1].ValueKN,
+		ComboName:       gov[0].Name,
+		Formula:         gov[0].Formula,
+		Governing:       gov,
+		Combinations:    combos,
+		LiveLoads:       live,
+		Notes:           "EN 1990 set B combinations 6.10a/6.10b and accidental, with characteristic, frequent and quasi-permanent SLS combinations.",
+	}
+	for _, k := range []string{KindCharacteristic, KindFrequent, KindQuasiPermanent} {
+		if g := Governing(sls[k]); len(g) > 0 {
+			res.Serviceability = append(res.Serviceability, g[0])
+		}
+	}
+	return res, nil
+}
+
+// bareCases turns the permanent and variable loads of Input into cases
+// with the factors of Method.
+func bareCases(in Input) []LoadCase {
+	gG, gQlong, gQshort, _ := factors(in.Method)
+	var cs []LoadCase
+	if in.LoadGKN != 0 {
+		cs = append(cs, LoadCase{Name: "G", Type: CaseDead, ValueKN: in.LoadGKN, GammaF: gG})
+	}
+	if in.LoadQLongKN != 0 {
+		cs = append(cs, LoadCase{Name: "Ql", Type: CaseLiveLong, ValueKN: in.LoadQLongKN, GammaF: gQlong})
+	}
+	if in.LoadQShortKN != 0 {
+		cs = append(cs, LoadCase{Name: "Qt", Type: CaseLiveShort, ValueKN: in.LoadQShortKN, GammaF: gQshort})
+	}
+	return cs
 }
 
 func factors(method Method) (gG, gQlong, gQshort float64, name string) {
@@ -0,0 +1,327 @@
+package loads
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type CaseType string
+
+const (
+	CaseDead        CaseType = "dead"
+	CaseLiveLong    CaseType = "live_long"
+	CaseLiveShort   CaseType = "live_short"
+	CaseSnow        CaseType = "snow"
+	CaseWind        CaseType = "wind"
+	CaseTemperature CaseType = "temperature"
+	CaseCrane       CaseType = "crane"
+	CaseSpecial     CaseType = "special"
+)
+
+// LoadCase is one named characteristic load. Cases sharing a Group are
+// mutually exclusive (wind + and wind -, crane positions). A positive Psi
+// replaces the SP20 combination factor for this case. Category selects the
+// EN 1990 ψ factors, which Psi0, Psi1 and Psi2 override when given, zero
+// included.
+type LoadCase struct {
+	Name      string   `json:"name"`
+	Type      CaseType `json:"type"`
+	ValueKN   float64  `json:"value_kn"`
+	GammaF    float64  `json:"gamma_f"`
+	GammaFMin float64  `json:"gamma_f_min"`
+	Psi       float64  `json:"psi"`
+	Group     string   `json:"group"`
+	Category  string   `json:"category"`
+	Psi0      *float64 `json:"psi0"`
+	Psi1      *float64 `json:"psi1"`
+	Psi2      *float64 `json:"psi2"`
+}
+
+type Combination struct {
+	Name    string  `json:"name"`
+	Kind    string  `json:"kind"`
+	ValueKN float64 `json:"value_kn"`
+	Formula string  `json:"formula"`
+}
+
+type duration int
+
+const (
+	durPermanent duration = iota
+	durLong
+	durShort
+	durSpecial
+)
+
+// Default γf by case type, SP20 sections 7-11.
+var defaultGammaF = map[CaseType]float64{
+	CaseDead:        1.1,
+	CaseLiveLong:    1.2,
+	CaseLiveShort:   1.2,
+	CaseSnow:        1.4,
+	CaseWind:        1.4,
+	CaseTemperature: 1.1,
+	CaseCrane:       1.1,
+	CaseSpecial:     1.0,
+}
+
+const maxVariableCases = 10
+
+func (t CaseType) duration() (duration, bool) {
+	switch t {
+	case CaseDead:
+		return durPermanent, true
+	case CaseLiveLong:
+		return durLong, true
+	case CaseLiveShort, CaseSnow, CaseWind, CaseTemperature, CaseCrane:
+		return durShort, true
+	case CaseSpecial:
+		return durSpecial, true
+	}
+	return 0, false
+}
+
+// term is one load in a combination with its factors.
+type term struct {
+	c     LoadCase
+	gamma float64
+	psi   float64
+}
+
+func (t term) value() float64 { return t.psi * t.gamma * t.c.ValueKN }
+
+// Combine generates every basic and special combination of SP20 section 6
+// from the load cases. Permanent loads enter with γf and, when it is
+// unfavourable, with γf,min. In basic combinations long loads take ψl = 1
+// and short loads ψt = 1.0, 0.9, 0.7 in order of their effect; in special
+// combinations ψl = 0.95 and ψt = 0.8 with one special load.
+func Combine(cases []LoadCase) ([]Combination, error) {
+	perm, variable, special, err := splitCases(cases)
+	if err != nil {
+		return nil, err
+	}
+	for _, cs := range [][]LoadCase{perm, variable, special} {
+		for i := range cs {
+			if cs[i].GammaF <= 0 {
+				cs[i].GammaF = defaultGammaF[cs[i].Type]
+			}
+		}
+	}
+	for i := range perm {
+		if perm[i].GammaFMin <= 0 {
+			perm[i].GammaFMin = 0.9
+		}
+	}
+
+	var out []Combination
+	count := map[string]int{}
+	emit := func(kind string, terms []term) {
+		if len(terms) == 0 {
+			return
+		}
+		count[kind]++
+		out = append(out, Combination{
+			Name:    fmt.Sprintf("%s %d", kind, count[kind]),
+			Kind:    kind,
+			ValueKN: sumTerms(terms),
+			Formula: formula(terms),
+		})
+	}
+	permSets := [][]term{permTerms(perm, false)}
+	if hasFavourable(perm) {
+		permSets = append(permSets, permTerms(perm, true))
+	}
+	for _, sub := range subsets(variable) {
+		for _, p := range permSets {
+			emit("basic", append(append([]term{}, p...), variableTerms(sub, false)...))
+		}
+	}
+	for _, s := range special {
+		for _, sub := range subsets(variable) {
+			for _, p := range permSets {
+				ts := append(append([]term{}, p...), variableTerms(sub, true)...)
+				emit("special", append(ts, term{c: s, gamma: s.GammaF, psi: 1}))
+			}
+		}
+	}
+	return out, nil
+}
+
+// splitCases names the cases and sorts them into permanent, variable and
+// special ones.
+func splitCases(cases []LoadCase) (perm, variable, special []LoadCase, err error) {
+	for i, c := range cases {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("L%d", i+1)
+		}
+		d, ok := c.Type.duration()
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("invalid load case type %q", c.Type)
+		}
+		switch d {
+		case durPermanent:
+			perm = append(perm, c)
+		case durSpecial:
+			special = append(special, c)
+		default:
+			variable = append(variable, c)
+		}
+	}
+	if len(perm)+len(variable)+len(special) == 0 {
+		return nil, nil, nil, fmt.Errorf("no load cases")
+	}
+	if len(variable) > maxVariableCases {
+		return nil, nil, nil, fmt.Errorf("too many variable load cases")
+	}
+	return perm, variable, special, nil
+}
+
+// Governing returns the combinations with the largest and the smallest
+// value; the second is omitted when it is the same combination.
+func Governing(combos []Combination) []Combination {
+	if len(combos) == 0 {
+		return nil
+	}
+	hi, lo := 0, 0
+	for i, c := range combos {
+		if c.ValueKN > combos[hi].ValueKN {
+			hi = i
+		}
+		if c.ValueKN < combos[lo].ValueKN {
+			lo = i
+		}
+	}
+	if hi == lo {
+		return []Combination{combos[hi]}
+	}
+	return []Combination{combos[hi], combos[lo]}
+}
+
+func permTerms(perm []LoadCase, favourable bool) []term {
+	ts := make([]term, 0, len(perm))
+	for _, c := range perm {
+		g := c.GammaF
+		if favourable {
+			g = c.GammaFMin
+		}
+		ts = append(ts, term{c: c, gamma: g, psi: 1})
+	}
+	return ts
+}
+
+func hasFavourable(perm []LoadCase) bool {
+	for _, c := range perm {
+		if c.GammaFMin != c.GammaF {
+			return true
+		}
+	}
+	return false
+}
+
+// variableTerms assigns combination factors. Short loads are ranked by the
+// magnitude of their design value; the leading one gets ψt1.
+func variableTerms(sub []LoadCase, special bool) []term {
+	var long, short []term
+	for _, c := range sub {
+		t := term{c: c, gamma: c.GammaF, psi: 1}
+		if d, _ := c.Type.duration(); d == durLong {
+			long = append(long, t)
+		} else {
+			short = append(short, t)
+		}
+	}
+	sort.SliceStable(short, func(i, j int) bool {
+		return math.Abs(short[i].value()) > math.Abs(short[j].value())
+	})
+	for i := range long {
+		if special {
+			long[i].psi = 0.95
+		}
+	}
+	for i := range short {
+		switch {
+		case special:
+			short[i].psi = 0.8
+		case i == 1:
+			short[i].psi = 0.9
+		case i > 1:
+			short[i].psi = 0.7
+		}
+	}
+	ts := append(long, short...)
+	for i := range ts {
+		if ts[i].c.Psi > 0 {
+			ts[i].psi = ts[i].c.Psi
+		}
+	}
+	return ts
+}
+
+// subsets lists every selection of variable loads with at most one case of
+// each exclusive group, including the empty one.
+func subsets(cases []LoadCase) [][]LoadCase {
+	var out [][]LoadCase
+	for _, idx := range subsetIndices(cases) {
+		sub := make([]LoadCase, len(idx))
+		for i, j := range idx {
+			sub[i] = cases[j]
+		}
+		out = append(out, sub)
+	}
+	return out
+}
+
+// subsetIndices is subsets as indices into cases.
+func subsetIndices(cases []LoadCase) [][]int {
+	var out [][]int
+	for mask := 0; mask < 1<<len(cases); mask++ {
+		var sub []int
+		groups := map[string]bool{}
+		ok := true
+		for i, c := range cases {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			if c.Group != "" {
+				if groups[c.Group] {
+					ok = false
+					break
+				}
+				groups[c.Group] = true
+			}
+			sub = append(sub, i)
+		}
+		if ok {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+func sumTerms(ts []term) float64 {
+	s := 0.0
+	for _, t := range ts {
+		s += t.value()
+	}
+	return s
+}
+
+// formula renders terms as "1.1·Dead + 0.9·1.4·Wind+".
+func formula(ts []term) string {
+	parts := make([]string, 0, len(ts))
+	for _, t := range ts {
+		p := num(t.gamma) + "·" + t.c.Name
+		if t.psi != 1 {
+			p = num(t.psi) + "·" + p
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, " + ")
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
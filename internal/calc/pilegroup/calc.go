@@ -0,0 +1,191 @@
+package pilegroup
+
+import (
+	"fmt"
+	"math"
+
+	piles "Vertex/internal/calc/piles"
+)
+
+// Grid lays out Rows x Cols piles at the given spacing, 3d by default.
+type Grid struct {
+	Rows      int     `json:"rows"`
+	Cols      int     `json:"cols"`
+	SpacingXM float64 `json:"spacing_x_m"`
+	SpacingYM float64 `json:"spacing_y_m"`
+}
+
+// Input loads a rigid cap with design forces at the centroid of the piles.
+// Positions take precedence over Grid; with neither the smallest square-ish
+// grid that carries the loads is chosen. Pile describes one pile and its
+// soil; its loads only feed the settlement.
+type Input struct {
+	Pile        piles.Input     `json:"pile"`
+	Positions   []piles.PilePos `json:"positions"`
+	Grid        *Grid           `json:"grid"`
+	NKN         float64         `json:"n_kn"`
+	MxKNm       float64         `json:"mx_knm"`
+	MyKNm       float64         `json:"my_knm"`
+	QKN         float64         `json:"q_kn"`
+	CapWeightKN float64         `json:"cap_weight_kn"`
+}
+
+type PileForce struct {
+	XM      float64 `json:"x_m"`
+	YM      float64 `json:"y_m"`
+	NKN     float64 `json:"n_kn"`
+	Tension bool    `json:"tension"`
+}
+
+type Result struct {
+	Piles        []PileForce  `json:"piles"`
+	PileCount    int          `json:"pile_count"`
+	SumX2        float64      `json:"sum_x2"`
+	SumY2        float64      `json:"sum_y2"`
+	MaxKN        float64      `json:"max_kn"`
+	MinKN        float64      `json:"min_kn"`
+	QPerPileKN   float64      `json:"q_per_pile_kn"`
+	ResistanceKN float64      `json:"resistance_kn"`
+	Utilization  float64      `json:"utilization"`
+	TensionPiles int          `json:"tension_piles"`
+	MinSpacingM  float64      `json:"min_spacing_m"`
+	SpacingOK    bool         `json:"spacing_ok"`
+	OK           bool         `json:"ok"`
+	Single       piles.Result `json:"single"`
+	Notes        string       `json:"notes"`
+}
+
+// maxAuto bounds the automatic layout.
+const maxAuto = 100
+
+func Calculate(in Input) (Result, error) {
+	if in.NKN <= 0 {
+		return Result{}, fmt.Errorf("invalid axial load")
+	}
+	single, err := piles.Calculate(in.Pile)
+	if err != nil {
+		return Result{}, err
+	}
+	rd := resistance(single)
+	if rd <= 0 {
+		return Result{}, fmt.Errorf("no pile resistance")
+	}
+	d := in.Pile.DiameterM
+	if in.Pile.PileType == "square" {
+		d = in.Pile.SideM
+	}
+	// Minimum spacing of hanging piles is 3d, SP24 8.13
+	minSpacing := 3 * d
+
+	var res Result
+	switch {
+	case len(in.Positions) > 0:
+		res = distribute(in, in.Positions, rd)
+	case in.Grid != nil:
+		g := *in.Grid
+		if g.Rows <= 0 || g.Cols <= 0 {
+			return Result{}, fmt.Errorf("invalid grid")
+		}
+		if g.SpacingXM <= 0 {
+			g.SpacingXM = minSpacing
+		}
+		if g.SpacingYM <= 0 {
+			g.SpacingYM = minSpacing
+		}
+		res = distribute(in, grid(g), rd)
+	default:
+		n := int(math.Ceil((in.NKN + in.CapWeightKN) / rd))
+		for ; n <= maxAuto; n++ {
+			rows := int(math.Ceil(math.Sqrt(float64(n))))
+			cols := int(math.Ceil(float64(n) / float64(rows)))
+			res = distribute(in, grid(Grid{Rows: rows, Cols: cols, SpacingXM: minSpacing, SpacingYM: minSpacing}), rd)
+			if res.OK {
+				break
+			}
+			// Skip to the next full grid
+			n = rows * cols
+		}
+	}
+
+	res.SpacingOK = res.PileCount == 1 || res.MinSpacingM >= minSpacing-1e-9
+	res.OK = res.OK && res.SpacingOK
+
+	pile := in.Pile
+	pile.Group = make([]piles.PilePos, len(res.Piles))
+	for i, p := range res.Piles {
+		pile.Group[i] = piles.PilePos{XM: p.XM, YM: p.YM}
+	}
+	if res.Single, err = piles.Calculate(pile); err != nil {
+		return Result{}, err
+	}
+	res.Notes = "Rigid cap: N/n ± My·x/Σx² ± Mx·y/Σy² about the pile centroid; minimum spacing 3d."
+	return res, nil
+}
+
+// resistance is the design resistance of one pile. Under EC7 the pile loads
+// need not be given, so the weakest combination is taken rather than the
+// one they make govern.
+func resistance(r piles.Result) float64 {
+	rd := r.DesignResistanceKN
+	for _, c := range r.EC7 {
+		rd = math.Min(rd, c.DesignResistanceKN)
+	}
+	return rd
+}
+
+func grid(g Grid) []piles.PilePos {
+	var out []piles.PilePos
+	for i := 0; i < g.Rows; i++ {
+		for j := 0; j < g.Cols; j++ {
+			out = append(out, piles.PilePos{XM: float64(j) * g.SpacingXM, YM: float64(i) * g.SpacingYM})
+		}
+	}
+	return out
+}
+
+// distribute shares the loads among the piles about their centroid.
+func distribute(in Input, pos []piles.PilePos, resistance float64) Result {
+	n := float64(len(pos))
+	cx, cy := 0.0, 0.0
+	for _, p := range pos {
+		cx += p.XM / n
+		cy += p.YM / n
+	}
+	res := Result{PileCount: len(pos), QPerPileKN: in.QKN / n, ResistanceKN: resistance}
+	for _, p := range pos {
+		res.SumX2 += (p.XM - cx) * (p.XM - cx)
+		res.SumY2 += (p.YM - cy) * (p.YM - cy)
+	}
+	res.MaxKN, res.MinKN = math.Inf(-1), math.Inf(1)
+	for _, p := range pos {
+		x, y := p.XM-cx, p.YM-cy
+		f := (in.NKN + in.CapWeightKN) / n
+		if res.SumX2 > 0 {
+			f += in.MyKNm * x / res.SumX2
+		}
+		if res.SumY2 > 0 {
+			f += in.MxKNm * y / res.SumY2
+		}
+		pf := PileForce{XM: x, YM: y, NKN: f, Tension: f < 0}
+		if pf.Tension {
+			res.TensionPiles++
+		}
+		res.Piles = append(res.Piles, pf)
+		res.MaxKN = math.Max(res.MaxKN, f)
+		res.MinKN = math.Min(res.MinKN, f)
+	}
+	res.MinSpacingM = math.Inf(1)
+	for i := range pos {
+		for j := i + 1; j < len(pos); j++ {
+			res.MinSpacingM = math.Min(res.MinSpacingM, math.Hypot(pos[i].XM-pos[j].XM, pos[i].YM-pos[j].YM))
+		}
+	}
+	if len(pos) == 1 {
+		res.MinSpacingM = 0
+	}
+	res.Utilization = res.MaxKN / resistance
+	// A single row cannot take a moment about its own line
+	balanced := (in.MyKNm == 0 || res.SumX2 > 0) && (in.MxKNm == 0 || res.SumY2 > 0)
+	res.OK = balanced && res.MaxKN <= resistance && res.TensionPiles == 0
+	return res
+}
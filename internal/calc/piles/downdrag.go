@@ -0,0 +1,95 @@
+package piles
+
+import "math"
+
+// fiSoft is the negative friction of settling soils that get no fi from
+// table 7.3 (IL above 1.0, peat), kPa.
+const fiSoft = 5.0
+
+func hasSettling(layers []Layer) bool {
+	for _, l := range layers {
+		if l.Settling {
+			return true
+		}
+	}
+	return false
+}
+
+// soilSettlement returns the settlement (m) at depth z of the settling
+// layers under the surcharge, summed below z with β = 0.8. ok is false
+// when a settling layer has no modulus.
+func soilSettlement(layers []Layer, q, z float64) (s float64, ok bool) {
+	for _, l := range layers {
+		if !l.Settling {
+			continue
+		}
+		if l.EMPa <= 0 {
+			return 0, false
+		}
+		h := l.ToDepthM - math.Max(l.FromDepthM, z)
+		if h > 0 {
+			s += 0.8 * q * h / (l.EMPa * 1000)
+		}
+	}
+	return s, true
+}
+
+// neutralPlane is the depth where the soil settles as much as the pile,
+// SP24 7.2.12. Without moduli or a pile settlement it is the bottom of the
+// lowest settling layer.
+func neutralPlane(in Input, pileSettlementM float64) float64 {
+	top := in.ToeDepthM - in.LengthM
+	if in.NeutralPlaneM > 0 {
+		return math.Min(math.Max(in.NeutralPlaneM, top), in.ToeDepthM)
+	}
+	lowest := top
+	for _, l := range in.Layers {
+		if l.Settling {
+			lowest = math.Max(lowest, l.ToDepthM)
+		}
+	}
+	lowest = math.Min(lowest, in.ToeDepthM)
+	if in.SurchargeKPa <= 0 || pileSettlementM <= 0 {
+		return lowest
+	}
+	for i := 0; top+0.1*float64(i) < lowest; i++ {
+		z := top + 0.1*float64(i)
+		s, ok := soilSettlement(in.Layers, in.SurchargeKPa, z)
+		if !ok {
+			return lowest
+		}
+		if s <= pileSettlementM {
+			return z
+		}
+	}
+	return lowest
+}
+
+// applyDowndrag drops the positive friction above the neutral plane and
+// sums the negative friction there; design subtracts it.
+func applyDowndrag(in Input, perimeter float64, res *Result) {
+	zn := neutralPlane(in, res.SettlementMM/1000)
+	res.NeutralPlaneM = zn
+	if s, ok := soilSettlement(in.Layers, in.SurchargeKPa, in.ToeDepthM-in.LengthM); ok {
+		res.SoilSettlementMM = s * 1000
+	}
+	lost, drag := 0.0, 0.0
+	for i := range res.ShaftSublayers {
+		sl := &res.ShaftSublayers[i]
+		h := math.Min(sl.ToDepthM, zn) - sl.FromDepthM
+		if h <= 0 {
+			continue
+		}
+		share := h / (sl.ToDepthM - sl.FromDepthM)
+		fi := sl.FiKPa
+		if fi <= 0 {
+			fi = fiSoft
+		}
+		sl.DowndragKN = fi * perimeter * h
+		lost += sl.ResistanceKN * share
+		sl.ResistanceKN *= 1 - share
+		drag += sl.DowndragKN
+	}
+	res.DowndragKN = drag
+	res.ShaftResistanceKN -= lost
+}
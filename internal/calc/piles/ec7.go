@@ -0,0 +1,184 @@
+package piles
+
+import (
+	"fmt"
+	"math"
+)
+
+// EC7Combination is one set of partial factors of a design approach of
+// EN 1997-1 and the pile count it needs.
+type EC7Combination struct {
+	Name               string  `json:"name"`
+	Actions            string  `json:"actions"`
+	Materials          string  `json:"materials"`
+	Resistances        string  `json:"resistances"`
+	GammaG             float64 `json:"gamma_g"`
+	GammaQ             float64 `json:"gamma_q"`
+	GammaB             float64 `json:"gamma_b"`
+	GammaS             float64 `json:"gamma_s"`
+	GammaT             float64 `json:"gamma_t"`
+	Xi                 float64 `json:"xi"`
+	DesignLoadKN       float64 `json:"design_load_kn"`
+	DesignResistanceKN float64 `json:"design_resistance_kn"`
+	PileCount          int     `json:"pile_count"`
+	Governing          bool    `json:"governing"`
+}
+
+type ec7Set struct{ name, a, m, r string }
+
+// Design approaches for axially loaded piles, EN 1997-1 2.4.7.3.4. DA1
+// combination 2 uses M1 for pile resistances.
+var ec7Approaches = map[int][]ec7Set{
+	1: {{"DA1-C1", "A1", "M1", "R1"}, {"DA1-C2", "A2", "M1", "R4"}},
+	2: {{"DA2", "A1", "M1", "R2"}},
+	3: {{"DA3", "A1", "M2", "R3"}},
+}
+
+// γG and γQ of table A.3.
+var ec7Actions = map[string][2]float64{
+	"A1": {1.35, 1.5},
+	"A2": {1.0, 1.3},
+}
+
+// γb, γs and γt of tables A.6 (driven), A.7 (bored) and A.8 (CFA, used for
+// screw piles).
+var ec7Resistances = map[string]map[string][3]float64{
+	"R1": {InstallDriven: {1.0, 1.0, 1.0}, InstallBored: {1.25, 1.0, 1.15}, InstallScrew: {1.1, 1.0, 1.1}},
+	"R2": {InstallDriven: {1.1, 1.1, 1.1}, InstallBored: {1.1, 1.1, 1.1}, InstallScrew: {1.1, 1.1, 1.1}},
+	"R3": {InstallDriven: {1.0, 1.0, 1.0}, InstallBored: {1.0, 1.0, 1.0}, InstallScrew: {1.0, 1.0, 1.0}},
+	"R4": {InstallDriven: {1.3, 1.3, 1.3}, InstallBored: {1.6, 1.3, 1.5}, InstallScrew: {1.45, 1.3, 1.4}},
+}
+
+// M2 factors of table A.4 on the unit resistances: γcu for clays and γφ'
+// otherwise.
+const (
+	ec7GammaCu  = 1.4
+	ec7GammaPhi = 1.25
+)
+
+// Correlation factors by the number of profiles, table A.10 (ξ3, ξ4), and
+// of static load tests, table A.9 (ξ1, ξ2).
+var (
+	xiProfiles = []float64{1, 2, 3, 4, 5, 7, 10}
+	xi3        = []float64{1.40, 1.35, 1.33, 1.31, 1.29, 1.27, 1.25}
+	xi4        = []float64{1.40, 1.27, 1.23, 1.20, 1.15, 1.12, 1.08}
+	xiTests    = []float64{1, 2, 3, 4, 5}
+	xi1        = []float64{1.40, 1.30, 1.20, 1.10, 1.00}
+	xi2        = []float64{1.40, 1.20, 1.05, 1.00, 1.00}
+)
+
+// stepXi returns the factor of the largest tabulated count not above n.
+func stepXi(ns, xs []float64, n int) float64 {
+	v := xs[0]
+	for i, k := range ns {
+		if float64(n) >= k {
+			v = xs[i]
+		}
+	}
+	return v
+}
+
+// ec7Design applies every combination of the design approach and keeps
+// the one that needs the most piles. Resistances come from the profiles
+// with ξ3/ξ4 or, when given, from static load tests with ξ1/ξ2 and γt.
+// Downdrag is a permanent action on each pile.
+func ec7Design(in Input, res *Result) error {
+	approach := in.DesignApproach
+	if approach == 0 {
+		approach = 2
+	}
+	sets, ok := ec7Approaches[approach]
+	if !ok {
+		return fmt.Errorf("invalid design approach")
+	}
+	install := in.Installation
+	if install == "" {
+		install = InstallDriven
+	}
+	profiles := in.Profiles
+	if profiles < 1 {
+		profiles = 1
+	}
+
+	// Characteristic resistances with M1 and with M2
+	shaftM2 := 0.0
+	for _, sl := range res.ShaftSublayers {
+		g := ec7GammaPhi
+		if soilType(sl.SoilType) == SoilClay {
+			g = ec7GammaCu
+		}
+		shaftM2 += sl.ResistanceKN / g
+	}
+	baseM2 := res.BaseResistanceKN / ec7GammaPhi
+	if toe, ok := layerAtDepth(in.Layers, in.ToeDepthM); ok && soilType(toe.SoilType) == SoilClay {
+		baseM2 = res.BaseResistanceKN / ec7GammaCu
+	}
+
+	// A combination without resistance governs outright
+	need := func(c EC7Combination) int {
+		if c.DesignResistanceKN <= 0 {
+			return math.MaxInt
+		}
+		return c.PileCount
+	}
+	res.EC7 = nil
+	worst := -1
+	for _, s := range sets {
+		act := ec7Actions[s.a]
+		rf := ec7Resistances[s.r][install]
+		c := EC7Combination{
+			Name:        s.name,
+			Actions:     s.a,
+			Materials:   s.m,
+			Resistances: s.r,
+			GammaG:      act[0],
+			GammaQ:      act[1],
+			GammaB:      rf[0],
+			GammaS:      rf[1],
+			GammaT:      rf[2],
+		}
+		c.DesignLoadKN = act[0]*in.LoadGKN + act[1]*(in.LoadQLongKN+in.LoadQShortKN)
+		if len(in.LoadTestsKN) > 0 {
+			mean, low := 0.0, math.Inf(1)
+			for _, r := range in.LoadTestsKN {
+				mean += r / float64(len(in.LoadTestsKN))
+				low = math.Min(low, r)
+			}
+			x1 := stepXi(xiTests, xi1, len(in.LoadTestsKN))
+			x2 := stepXi(xiTests, xi2, len(in.LoadTestsKN))
+			rk := math.Min(mean/x1, low/x2)
+			c.Xi = mean / rk
+			c.DesignResistanceKN = rk / rf[2]
+		} else {
+			// One calculated value is both the mean and the minimum
+			c.Xi = math.Max(stepXi(xiProfiles, xi3, profiles), stepXi(xiProfiles, xi4, profiles))
+			shaft, base := res.ShaftResistanceKN, res.BaseResistanceKN
+			if s.m == "M2" {
+				shaft, base = shaftM2, baseM2
+			}
+			c.DesignResistanceKN = base/c.Xi/rf[0] + shaft/c.Xi/rf[1]
+		}
+		c.DesignResistanceKN -= act[0] * res.DowndragKN
+		if c.DesignResistanceKN > 0 {
+			c.PileCount = int(math.Max(1, math.Ceil(c.DesignLoadKN/c.DesignResistanceKN)))
+		}
+		res.EC7 = append(res.EC7, c)
+		i := len(res.EC7) - 1
+		if worst < 0 {
+			worst = i
+			continue
+		}
+		w := res.EC7[worst]
+		if need(c) > need(w) || need(c) == need(w) && c.DesignLoadKN*w.DesignResistanceKN > w.DesignLoadKN*c.DesignResistanceKN {
+			worst = i
+		}
+	}
+	g := &res.EC7[worst]
+	g.Governing = true
+	res.DesignLoadKN, res.DesignResistanceKN, res.PileCount = g.DesignLoadKN, g.DesignResistanceKN, g.PileCount
+	res.AverageLoadPerPile = 0
+	if g.PileCount > 0 {
+		res.AverageLoadPerPile = g.DesignLoadKN / float64(g.PileCount)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package piles
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 )
@@ -23,3 +24,74 @@ func (h *Handler) Calc(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
+
+// CPT takes a multipart form with the sounding in "file" (CSV or xlsx) and
+// the pile in "input" as JSON.
+func (h *Handler) CPT(w http.ResponseWriter, r *http.Request) {
+	var input Input
+	if err := json.Unmarshal([]byte(r.FormValue("input")), &input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if input.CPT, err = ParseCPT(file, header.Filename); err != nil {
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return
+	}
+	res, err := Calculate(input)
+	if err != nil {
+		http.Error(w, "Calculation error", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// Export fills the reference workbook with the input and the results and
+// returns it for download.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	var input WorkbookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	res, err := Calculate(input.Input)
+	if err != nil {
+		http.Error(w, "Calculation error", http.StatusBadRequest)
+		return
+	}
+	path := h.WorkbookPath
+	if path == "" {
+		path = DefaultWorkbook
+	}
+	var buf bytes.Buffer
+	if err := WriteWorkbook(&buf, path, input, res); err != nil {
+		http.Error(w, "Workbook generation error", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.ms-excel.sheet.macroEnabled.12")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"piles.xlsm\"")
+	buf.WriteTo(w)
+}
+
+// Import reads the layers, pile and loads from a workbook in "file".
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	input, err := ParseWorkbook(file)
+	if err != nil {
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(input)
+}
@@ -14,37 +14,85 @@ const (
 )
 
 type Layer struct {
-	FromDepthM float64 `json:"from_depth_m"`
-	ToDepthM   float64 `json:"to_depth_m"`
-	SoilType   string  `json:"soil_type"`
-	GammaKNM3  float64 `json:"gamma_kn_m3"`
-	FiKPa      float64 `json:"fi_kpa"`
-	Alpha      float64 `json:"alpha"`
+	FromDepthM float64  `json:"from_depth_m"`
+	ToDepthM   float64  `json:"to_depth_m"`
+	SoilType   string   `json:"soil_type"`
+	GammaKNM3  float64  `json:"gamma_kn_m3"`
+	FiKPa      float64  `json:"fi_kpa"`
+	Alpha      float64  `json:"alpha"`
+	IL         *float64 `json:"il"`
+	EMPa       float64  `json:"e_mpa"`
+	GMPa       float64  `json:"g_mpa"`
+	Poisson    float64  `json:"poisson"`
+	PhiDeg     float64  `json:"phi_deg"`
+	CKPa       float64  `json:"c_kpa"`
+	KKNm4      float64  `json:"k_kn_m4"`
+	Settling   bool     `json:"settling"`
+	NoFriction bool     `json:"no_friction"`
 }
 
 type Input struct {
-	Method      Method  `json:"method"`
-	PileType    string  `json:"pile_type"`
-	SideM       float64 `json:"side_m"`
-	DiameterM   float64 `json:"diameter_m"`
-	LengthM     float64 `json:"length_m"`
-	ToeDepthM   float64 `json:"toe_depth_m"`
-	BaseQbKPa   float64 `json:"base_qb_kpa"`
-	LoadGKN     float64 `json:"load_g_kn"`
-	LoadQLongKN float64 `json:"load_q_long_kn"`
-	LoadQShortKN float64 `json:"load_q_short_kn"`
-	Layers      []Layer `json:"layers"`
+	Method            Method        `json:"method"`
+	PileType          string        `json:"pile_type"`
+	SideM             float64       `json:"side_m"`
+	DiameterM         float64       `json:"diameter_m"`
+	LengthM           float64       `json:"length_m"`
+	ToeDepthM         float64       `json:"toe_depth_m"`
+	BaseQbKPa         float64       `json:"base_qb_kpa"`
+	LoadGKN           float64       `json:"load_g_kn"`
+	LoadQLongKN       float64       `json:"load_q_long_kn"`
+	LoadQShortKN      float64       `json:"load_q_short_kn"`
+	Layers            []Layer       `json:"layers"`
+	PileEMPa          float64       `json:"pile_e_mpa"`
+	Group             []PilePos     `json:"group"`
+	GroupMethod       string        `json:"group_method"`
+	SettlementLimitMM float64       `json:"settlement_limit_mm"`
+	Installation      string        `json:"installation"`
+	Lateral           *LateralInput `json:"lateral"`
+	SurchargeKPa      float64       `json:"surcharge_kpa"`
+	NeutralPlaneM     float64       `json:"neutral_plane_m"`
+	CPT               []CPTPoint    `json:"cpt"`
+	DesignApproach    int           `json:"design_approach"`
+	Profiles          int           `json:"profiles"`
+	LoadTestsKN       []float64     `json:"load_tests_kn"`
 }
 
 type Result struct {
-	DesignLoadKN       float64 `json:"design_load_kn"`
-	DesignResistanceKN float64 `json:"design_resistance_kn"`
-	ShaftResistanceKN  float64 `json:"shaft_resistance_kn"`
-	BaseResistanceKN   float64 `json:"base_resistance_kn"`
-	PileCount          int     `json:"pile_count"`
-	AverageLoadPerPile float64 `json:"average_load_per_pile"`
-	MethodUsed         Method  `json:"method_used"`
-	Notes              string  `json:"notes"`
+	DesignLoadKN       float64          `json:"design_load_kn"`
+	DesignResistanceKN float64          `json:"design_resistance_kn"`
+	ShaftResistanceKN  float64          `json:"shaft_resistance_kn"`
+	BaseResistanceKN   float64          `json:"base_resistance_kn"`
+	PileCount          int              `json:"pile_count"`
+	AverageLoadPerPile float64          `json:"average_load_per_pile"`
+	MethodUsed         Method           `json:"method_used"`
+	Notes              string           `json:"notes"`
+	SettlementMM       float64          `json:"settlement_mm"`
+	GroupSettlementMM  float64          `json:"group_settlement_mm"`
+	CompressibleDepthM float64          `json:"compressible_depth_m,omitempty"`
+	SettlementLimitMM  float64          `json:"settlement_limit_mm"`
+	SettlementOK       bool             `json:"settlement_ok"`
+	Installation       string           `json:"installation"`
+	GammaCR            float64          `json:"gamma_cr"`
+	GammaCf            float64          `json:"gamma_cf"`
+	BaseRKPa           float64          `json:"base_r_kpa"`
+	ShaftSublayers     []ShaftSublayer  `json:"shaft_sublayers"`
+	Lateral            *LateralResult   `json:"lateral,omitempty"`
+	NeutralPlaneM      float64          `json:"neutral_plane_m,omitempty"`
+	DowndragKN         float64          `json:"downdrag_kn,omitempty"`
+	SoilSettlementMM   float64          `json:"soil_settlement_mm,omitempty"`
+	CPT                *CPTResult       `json:"cpt,omitempty"`
+	EC7                []EC7Combination `json:"ec7,omitempty"`
+}
+
+// ShaftSublayer is a slice of the shaft no thicker than 2 m with fi taken
+// at its mid-depth.
+type ShaftSublayer struct {
+	FromDepthM   float64 `json:"from_depth_m"`
+	ToDepthM     float64 `json:"to_depth_m"`
+	FiKPa        float64 `json:"fi_kpa"`
+	ResistanceKN float64 `json:"resistance_kn"`
+	DowndragKN   float64 `json:"downdrag_kn,omitempty"`
+	SoilType     string  `json:"soil_type,omitempty"`
 }
 
 func Calculate(input Input) (Result, error) {
@@ -60,7 +108,7 @@ func Calculate(input Input) (Result, error) {
 	if input.PileType == "round" && input.DiameterM <= 0 {
 		return Result{}, fmt.Errorf("invalid diameter")
 	}
-	if len(input.Layers) == 0 {
+	if len(input.Layers) == 0 && len(input.CPT) == 0 {
 		return Result{}, fmt.Errorf("no layers provided")
 	}
 
@@ -74,57 +122,188 @@ func Calculate(input Input) (Result, error) {
 		area = math.Pi * input.DiameterM * input.DiameterM / 4
 	}
 
+	wf, ok := workingFactors[input.Installation]
+	if !ok {
+		return Result{}, fmt.Errorf("invalid installation method")
+	}
+	gammaCR, gammaCf := wf[0], wf[1]
+	// γc are SP24 factors; EN 1997 has its own resistance factors
+	if input.Method == MethodEC7 {
+		gammaCR, gammaCf = 1, 1
+	}
+
 	shaft := 0.0
-	top := input.ToeDepthM - input.LengthM
-	bottom := input.ToeDepthM
-	for _, layer := range input.Layers {
-		overlap := math.Min(layer.ToDepthM, bottom) - math.Max(layer.FromDepthM, top)
-		if overlap <= 0 {
-			continue
+	qb := input.BaseQbKPa
+	var subs []ShaftSublayer
+	var sounding *CPTResult
+	if len(input.CPT) > 0 {
+		// SP24 7.3.5 is for driven piles; β1 and β2 already include the
+		// installation, so γcR and γcf are not applied
+		if input.Installation != "" && input.Installation != InstallDriven {
+			return Result{}, fmt.Errorf("sounding method requires driven piles")
+		}
+		gammaCR, gammaCf = 1, 1
+		d := input.DiameterM
+		if input.PileType == "square" {
+			d = input.SideM
+		}
+		var rs, qs, b1 float64
+		var err error
+		shaft, rs, qs, b1, subs, err = cptResistance(input, perimeter, d)
+		if err != nil {
+			return Result{}, err
+		}
+		sounding = &CPTResult{MeanQcMPa: qs, Beta1: b1, Points: len(input.CPT)}
+		if qb <= 0 {
+			qb = rs
 		}
-		fi := layer.FiKPa
-		alpha := layer.Alpha
-		if alpha == 0 {
-			alpha = 1
+	} else {
+		// Layers without fi take it from SP24 table 7.3 in sublayers of at
+		// most 2 m. Layers without friction keep their sublayers at zero
+		// resistance so that downdrag still reaches them.
+		top := input.ToeDepthM - input.LengthM
+		bottom := input.ToeDepthM
+		for _, layer := range input.Layers {
+			from := math.Max(layer.FromDepthM, top)
+			overlap := math.Min(layer.ToDepthM, bottom) - from
+			if overlap <= 0 {
+				continue
+			}
+			alpha := layer.Alpha
+			if alpha == 0 {
+				alpha = 1
+			}
+			n := int(math.Ceil(overlap/maxSublayer - 1e-9))
+			h := overlap / float64(n)
+			for i := 0; i < n; i++ {
+				z0 := from + float64(i)*h
+				fi := layer.FiKPa
+				if fi <= 0 {
+					var err error
+					fi, err = tableFriction(layer, z0+h/2)
+					if err != nil && !layer.NoFriction {
+						return Result{}, err
+					}
+				}
+				r := gammaCf * fi * alpha * perimeter * h
+				if layer.NoFriction {
+					// fi stays for the negative friction; fiSoft when unknown
+					r = 0
+				}
+				subs = append(subs, ShaftSublayer{FromDepthM: z0, ToDepthM: z0 + h, FiKPa: fi, ResistanceKN: r, SoilType: layer.SoilType})
+				shaft += r
+			}
+		}
+
+		if qb <= 0 {
+			toe, ok := layerAtDepth(input.Layers, bottom)
+			if !ok {
+				return Result{}, fmt.Errorf("no layer at the pile toe")
+			}
+			var err error
+			qb, err = tableResistance(toe, bottom, input.Installation)
+			if err != nil {
+				return Result{}, err
+			}
 		}
-		shaft += fi * alpha * perimeter * overlap
 	}
+	base := gammaCR * qb * area
 
-	base := input.BaseQbKPa * area
-	totalResistance := shaft + base
+	res := Result{
+		ShaftResistanceKN: shaft,
+		BaseResistanceKN:  base,
+		MethodUsed:        input.Method,
+		Notes:             methodNotes(input),
+		Installation:      input.Installation,
+		GammaCR:           gammaCR,
+		GammaCf:           gammaCf,
+		BaseRKPa:          qb,
+		ShaftSublayers:    subs,
+		CPT:               sounding,
+	}
+	if err := design(input, &res); err != nil {
+		return Result{}, err
+	}
+	if hasStiffness(input.Layers) {
+		if err := pileSettlement(input, area, res.PileCount, &res); err != nil {
+			return Result{}, err
+		}
+	}
+	if hasSettling(input.Layers) {
+		applyDowndrag(input, perimeter, &res)
+		if err := design(input, &res); err != nil {
+			return Result{}, err
+		}
+		// The downdrag may add piles; settle the final count. The neutral
+		// plane stays where the first settlement put it.
+		if hasStiffness(input.Layers) {
+			if err := pileSettlement(input, area, res.PileCount, &res); err != nil {
+				return Result{}, err
+			}
+		}
+	}
+	if input.Lateral != nil {
+		lr, err := lateral(input, *input.Lateral)
+		if err != nil {
+			return Result{}, err
+		}
+		res.Lateral = &lr
+	}
+	return res, nil
+}
 
-	gammaG, gammaQLong, gammaQShort, gammaR := factors(input.Method)
-	designLoad := input.LoadGKN*gammaG + input.LoadQLongKN*gammaQLong + input.LoadQShortKN*gammaQShort
-	designResistance := totalResistance / gammaR
+// design sets the design load and resistance and the pile count from the
+// characteristic shaft and base resistance less the downdrag.
+func design(in Input, res *Result) error {
+	if in.Method == MethodEC7 {
+		return ec7Design(in, res)
+	}
+	gammaG, gammaQLong, gammaQShort, gammaR := factors(in.Method)
+	res.DesignLoadKN = in.LoadGKN*gammaG + in.LoadQLongKN*gammaQLong + in.LoadQShortKN*gammaQShort
+	res.DesignResistanceKN = (res.ShaftResistanceKN+res.BaseResistanceKN)/gammaR - res.DowndragKN
+	res.PileCount, res.AverageLoadPerPile = 0, 0
+	if res.DesignResistanceKN > 0 {
+		res.PileCount = int(math.Max(1, math.Ceil(res.DesignLoadKN/res.DesignResistanceKN)))
+		res.AverageLoadPerPile = res.DesignLoadKN / float64(res.PileCount)
+	}
+	return nil
+}
 
-	pileCount := 0
-	avg := 0.0
-	if designResistance > 0 {
-		pileCount = int(math.Ceil(designLoad / designResistance))
-		if pileCount < 1 {
-			pileCount = 1
+// methodNotes names where the resistances come from and which code the
+// design follows.
+func methodNotes(in Input) string {
+	var notes string
+	switch {
+	case len(in.CPT) > 0:
+		notes = "Resistances from static sounding, SP24 7.3.5 with β1 and β2 of table 7.16."
+	case in.BaseQbKPa > 0:
+		notes = "Shaft friction from SP24 table 7.3 where fi is not given; toe resistance as given."
+	default:
+		notes = "Shaft friction and toe resistance from SP24 tables 7.3 and 7.2 where not given."
+	}
+	switch in.Method {
+	case MethodEC7:
+		approach := in.DesignApproach
+		if approach == 0 {
+			approach = 2
+		}
+		notes += fmt.Sprintf(" Design to EN 1997-1, design approach %d", approach)
+		if len(in.LoadTestsKN) > 0 {
+			notes += ", resistance from static load tests"
 		}
-		avg = designLoad / float64(pileCount)
-	}
-
-	return Result{
-		DesignLoadKN:       designLoad,
-		DesignResistanceKN: designResistance,
-		ShaftResistanceKN:  shaft,
-		BaseResistanceKN:   base,
-		PileCount:          pileCount,
-		AverageLoadPerPile: avg,
-		MethodUsed:         input.Method,
-		Notes:              "Simplified calculation. Replace with full code-compliant formulas for production use.",
-	}, nil
+		notes += "."
+	case MethodSP22:
+		notes += " Design with the SP22 load factors and γc,g = 1.2."
+	default:
+		notes += " Design to SP24 with γc,g = 1.25."
+	}
+	return notes
 }
 
 func factors(method Method) (gammaG, gammaQLong, gammaQShort, gammaR float64) {
 	switch method {
 	case MethodSP22:
 		return 1.05, 1.2, 1.3, 1.2
-	case MethodEC7:
-		return 1.35, 1.5, 1.5, 1.1
 	default:
 		return 1.1, 1.2, 1.3, 1.25
 	}
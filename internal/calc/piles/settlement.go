@@ -0,0 +1,226 @@
+package piles
+
+import (
+	"fmt"
+	"math"
+
+	settlement "Vertex/internal/calc/settlement"
+)
+
+type PilePos struct {
+	XM float64 `json:"x_m"`
+	YM float64 `json:"y_m"`
+}
+
+const (
+	GroupInteraction = "interaction"
+	GroupConditional = "conditional"
+)
+
+// hasStiffness reports whether the layers carry deformation moduli, so a
+// settlement can be computed.
+func hasStiffness(layers []Layer) bool {
+	for _, l := range layers {
+		if l.EMPa > 0 || l.GMPa > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (l Layer) shear() (g, nu float64) {
+	nu = l.Poisson
+	if nu <= 0 {
+		nu = 0.3
+	}
+	g = l.GMPa
+	if g <= 0 {
+		g = l.EMPa / (2 * (1 + nu))
+	}
+	return g * 1000, nu
+}
+
+// averageShear returns the thickness-weighted shear modulus (kPa) and
+// Poisson's ratio of the layers between depths z0 and z1.
+func averageShear(layers []Layer, z0, z1 float64) (float64, float64, bool) {
+	sg, snu, sh := 0.0, 0.0, 0.0
+	for _, l := range layers {
+		h := math.Min(l.ToDepthM, z1) - math.Max(l.FromDepthM, z0)
+		if h <= 0 {
+			continue
+		}
+		g, nu := l.shear()
+		if g <= 0 {
+			continue
+		}
+		sg += g * h
+		snu += nu * h
+		sh += h
+	}
+	if sh == 0 {
+		return 0, 0, false
+	}
+	return sg / sh, snu / sh, true
+}
+
+// pileSoil holds what the single-pile settlement needs.
+type pileSoil struct {
+	g1, nu1, g2, nu2 float64
+	l, d, ea         float64
+}
+
+func newPileSoil(in Input, area float64) (pileSoil, error) {
+	top := in.ToeDepthM - in.LengthM
+	g1, nu1, ok := averageShear(in.Layers, top, in.ToeDepthM)
+	if !ok {
+		return pileSoil{}, fmt.Errorf("no moduli along the shaft")
+	}
+	// G2 is taken over 0.5·l below the toe or from the lowest layer
+	g2, nu2, ok := averageShear(in.Layers, in.ToeDepthM, in.ToeDepthM+0.5*in.LengthM)
+	if !ok {
+		last := in.Layers[len(in.Layers)-1]
+		g2, nu2 = last.shear()
+	}
+	if g2 <= 0 {
+		return pileSoil{}, fmt.Errorf("no moduli below the toe")
+	}
+	e := in.PileEMPa
+	if e <= 0 {
+		e = 30000
+	}
+	d := in.DiameterM
+	if in.PileType == "square" {
+		d = 1.13 * in.SideM
+	}
+	return pileSoil{g1: g1, nu1: nu1, g2: g2, nu2: nu2, l: in.LengthM, d: d, ea: e * 1000 * area}, nil
+}
+
+func kNu(nu float64) float64 {
+	return 2.82 - 3.78*nu + 2.18*nu*nu
+}
+
+// single returns the settlement (m) of a single pile under N (kN), SP24
+// (7.33)-(7.36).
+func (p pileSoil) single(n float64) float64 {
+	kv := kNu((p.nu1 + p.nu2) / 2)
+	kv1 := kNu(p.nu1)
+	betaR := 0.17 * math.Log(kv*p.g1*p.l/(p.g2*p.d))
+	alphaR := 0.17 * math.Log(kv1*p.l/p.d)
+	chi := p.ea / (p.g1 * p.l * p.l)
+	c := 2.12 * math.Pow(chi, 0.75)
+	lambda := c / (1 + c)
+	beta := betaR/lambda + (1-betaR/alphaR)/chi
+	return beta * n / (p.g1 * p.l)
+}
+
+// extra returns the additional settlement (m) from a pile at distance a
+// carrying N, SP24 7.4.4; it vanishes beyond the influence distance.
+func (p pileSoil) extra(n, a float64) float64 {
+	kv := kNu((p.nu1 + p.nu2) / 2)
+	delta := 0.17 * math.Log(kv*p.g1*p.l/(2*p.g2*a)) / (p.g1 * p.l)
+	return math.Max(delta, 0) * n
+}
+
+// groupInteraction returns the largest pile settlement of the group with
+// equal pile loads n.
+func (p pileSoil) groupInteraction(pos []PilePos, n float64) float64 {
+	worst := 0.0
+	for i, a := range pos {
+		s := p.single(n)
+		for j, b := range pos {
+			if i == j {
+				continue
+			}
+			dist := math.Hypot(a.XM-b.XM, a.YM-b.YM)
+			if dist <= 0 {
+				continue
+			}
+			s += p.extra(n, dist)
+		}
+		worst = math.Max(worst, s)
+	}
+	return worst
+}
+
+// groupConditional treats the group as a conditional footing at the toe,
+// widened by l·tan(φmt/4) on each side, SP24 7.4.6-7.4.8, and sums the
+// settlement of the soil below per SP22.
+func groupConditional(in Input, pos []PilePos, total, d float64) (settlement.Result, error) {
+	top := in.ToeDepthM - in.LengthM
+	x0, x1, y0, y1 := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, p := range pos {
+		x0, x1 = math.Min(x0, p.XM), math.Max(x1, p.XM)
+		y0, y1 = math.Min(y0, p.YM), math.Max(y1, p.YM)
+	}
+	sphi, sh := 0.0, 0.0
+	for _, l := range in.Layers {
+		h := math.Min(l.ToDepthM, in.ToeDepthM) - math.Max(l.FromDepthM, top)
+		if h > 0 {
+			sphi += l.PhiDeg * h
+			sh += h
+		}
+	}
+	widen := 0.0
+	if sh > 0 {
+		widen = in.LengthM * math.Tan(sphi/sh/4*math.Pi/180)
+	}
+	b := x1 - x0 + d + 2*widen
+	lc := y1 - y0 + d + 2*widen
+	if b > lc {
+		b, lc = lc, b
+	}
+
+	var soil []settlement.Layer
+	weight := 0.0
+	for _, l := range in.Layers {
+		soil = append(soil, settlement.Layer{TopM: l.FromDepthM, BottomM: l.ToDepthM, GammaKNm3: l.GammaKNM3, EMPa: l.EMPa})
+		weight += l.GammaKNM3 * math.Max(0, math.Min(l.ToDepthM, in.ToeDepthM)-l.FromDepthM)
+	}
+	// Soil and piles within the block weigh about as much as the soil
+	p := total/(b*lc) + weight
+	return settlement.Calculate(soil, settlement.Footing{BM: b, LM: lc, DepthM: in.ToeDepthM, PressureKPa: p}, 0.5)
+}
+
+// pileSettlement adds the settlement of a single pile and of the group
+// under the characteristic load and checks it against the limit (100 mm,
+// SP22 appendix Г for framed buildings, unless given).
+func pileSettlement(in Input, area float64, count int, res *Result) error {
+	soil, err := newPileSoil(in, area)
+	if err != nil {
+		return err
+	}
+	total := in.LoadGKN + in.LoadQLongKN + in.LoadQShortKN
+	n := count
+	if len(in.Group) > 0 {
+		n = len(in.Group)
+	}
+	if n < 1 {
+		n = 1
+	}
+	perPile := total / float64(n)
+	res.SettlementMM = soil.single(perPile) * 1000
+	res.GroupSettlementMM = res.SettlementMM
+
+	if len(in.Group) > 1 {
+		switch in.GroupMethod {
+		case GroupConditional:
+			s, err := groupConditional(in, in.Group, total, soil.d)
+			if err != nil {
+				return err
+			}
+			res.GroupSettlementMM = s.SettlementM * 1000
+			res.CompressibleDepthM = s.CompressibleDepthM
+		case GroupInteraction, "":
+			res.GroupSettlementMM = soil.groupInteraction(in.Group, perPile) * 1000
+		default:
+			return fmt.Errorf("invalid group method")
+		}
+	}
+
+	res.SettlementLimitMM = in.SettlementLimitMM
+	if res.SettlementLimitMM <= 0 {
+		res.SettlementLimitMM = 100
+	}
+	res.SettlementOK = math.Max(res.SettlementMM, res.GroupSettlementMM) <= res.SettlementLimitMM
+	return nil
+}
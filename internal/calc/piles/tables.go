@@ -0,0 +1,225 @@
+package piles
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Soil types understood by the normative tables. Clays, loams and sandy
+// loams use "clay" with the liquidity index IL.
+const (
+	SoilSandGravelly = "sand_gravelly"
+	SoilSandCoarse   = "sand_coarse"
+	SoilSandMedium   = "sand_medium"
+	SoilSandFine     = "sand_fine"
+	SoilSandSilty    = "sand_silty"
+	SoilClay         = "clay"
+)
+
+const (
+	InstallDriven = "driven"
+	InstallBored  = "bored"
+	InstallScrew  = "screw"
+)
+
+// workingFactors are γcR and γcf by installation method: hammer driving,
+// SP24 table 7.4; bored piles concreted without casing, table 7.6; screw
+// piles under compression, 7.2.10.
+var workingFactors = map[string][2]float64{
+	"":            {1.0, 1.0},
+	InstallDriven: {1.0, 1.0},
+	InstallBored:  {1.0, 0.7},
+	InstallScrew:  {0.8, 0.8},
+}
+
+// Toe resistance R of driven piles (kPa), SP24 table 7.2. Sands take the
+// sand values of the gravelly, coarse, medium, fine and silty columns; the
+// "—" column is clay only. Clays by IL 0 ... 0.6.
+var (
+	rDepths = []float64{3, 4, 5, 7, 10, 15, 20, 25, 30, 35}
+	rSand   = [][]float64{
+		{7500, 6600, 3100, 2000, 1100},
+		{8300, 6800, 3200, 2100, 1250},
+		{8800, 7000, 3400, 2200, 1300},
+		{9700, 7300, 3700, 2400, 1400},
+		{10500, 7700, 4000, 2600, 1500},
+		{11700, 8200, 4400, 2900, 1650},
+		{12600, 8500, 4800, 3200, 1800},
+		{13400, 9000, 5200, 3500, 1950},
+		{14200, 9500, 5600, 3800, 2100},
+		{15000, 10000, 6000, 4100, 2250},
+	}
+	rClayIL = []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6}
+	rClay   = [][]float64{
+		{7500, 4000, 3000, 2000, 1200, 1100, 600},
+		{8300, 5100, 3800, 2500, 1600, 1250, 700},
+		{8800, 6200, 4000, 2800, 2000, 1300, 800},
+		{9700, 6900, 4300, 3300, 2200, 1400, 850},
+		{10500, 7300, 5000, 3500, 2400, 1500, 900},
+		{11700, 7500, 5600, 4000, 2900, 1650, 1000},
+		{12600, 8500, 6200, 4500, 3200, 1800, 1100},
+		{13400, 9000, 6800, 5200, 3500, 1950, 1200},
+		{14200, 9500, 7400, 5600, 3800, 2100, 1300},
+		{15000, 10000, 8000, 6000, 4100, 2250, 1400},
+	}
+)
+
+// Toe resistance R of bored piles in clays (kPa), SP24 table 7.8.
+var (
+	rBoredDepths = []float64{3, 5, 7, 10, 12, 15, 18, 20, 30, 40}
+	rBoredIL     = []float64{0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6}
+	rBored       = [][]float64{
+		{850, 750, 650, 500, 400, 300, 250},
+		{1000, 850, 750, 650, 500, 400, 300},
+		{1150, 1000, 850, 750, 600, 500, 400},
+		{1350, 1200, 1050, 950, 800, 700, 500},
+		{1550, 1400, 1250, 1100, 950, 800, 600},
+		{1800, 1650, 1500, 1300, 1150, 1000, 700},
+		{2100, 1900, 1700, 1500, 1300, 1100, 800},
+		{2300, 2100, 1900, 1650, 1450, 1250, 900},
+		{3300, 3000, 2700, 2400, 2100, 1800, 1300},
+		{4500, 4000, 3500, 3000, 2500, 2000, 1400},
+	}
+)
+
+// Skin friction fi (kPa), SP24 table 7.3. Columns: coarse and medium sand /
+// clay IL ≤ 0.2, fine / 0.3, silty / 0.4, clay 0.5 ... 1.0.
+var (
+	fDepths = []float64{1, 2, 3, 4, 5, 6, 8, 10, 15, 20, 25, 30, 35}
+	fIL     = []float64{0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0}
+	fTable  = [][]float64{
+		{35, 23, 15, 12, 8, 4, 4, 3, 2},
+		{42, 30, 21, 17, 12, 7, 5, 4, 4},
+		{48, 35, 25, 20, 14, 8, 7, 6, 5},
+		{53, 38, 27, 22, 16, 9, 8, 7, 5},
+		{56, 40, 29, 24, 17, 10, 8, 7, 6},
+		{58, 42, 31, 25, 18, 10, 8, 7, 6},
+		{62, 44, 33, 26, 19, 10, 8, 7, 6},
+		{65, 46, 34, 27, 19, 10, 8, 7, 6},
+		{72, 51, 38, 28, 20, 11, 8, 7, 6},
+		{79, 56, 41, 30, 20, 12, 8, 7, 6},
+		{86, 61, 44, 32, 20, 12, 8, 7, 6},
+		{93, 66, 47, 34, 21, 12, 9, 8, 7},
+		{100, 70, 50, 36, 22, 13, 9, 8, 7},
+	}
+)
+
+// sandColumn indexes rSand.
+var sandColumn = map[string]int{
+	SoilSandGravelly: 0,
+	SoilSandCoarse:   1,
+	SoilSandMedium:   2,
+	SoilSandFine:     3,
+	SoilSandSilty:    4,
+}
+
+// sandFriction maps sands onto the columns of table 7.3.
+var sandFriction = map[string]float64{
+	SoilSandGravelly: 0.2,
+	SoilSandCoarse:   0.2,
+	SoilSandMedium:   0.2,
+	SoilSandFine:     0.3,
+	SoilSandSilty:    0.4,
+}
+
+// maxSublayer is the thickest sublayer the shaft is split into, SP24 7.2.2.
+const maxSublayer = 2.0
+
+func soilType(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// clayIL returns IL of a clay layer, which the tables cannot do without.
+func clayIL(l Layer) (float64, error) {
+	if l.IL == nil {
+		return 0, fmt.Errorf("clay layer without il")
+	}
+	return *l.IL, nil
+}
+
+// tableFriction returns fi at depth z, interpolated in depth and IL. Clays
+// softer than IL 1.0 contribute nothing.
+func tableFriction(l Layer, z float64) (float64, error) {
+	st := soilType(l.SoilType)
+	var il float64
+	if st == SoilClay {
+		var err error
+		if il, err = clayIL(l); err != nil {
+			return 0, err
+		}
+	} else {
+		v, ok := sandFriction[st]
+		if !ok {
+			return 0, fmt.Errorf("unknown soil type %q", l.SoilType)
+		}
+		il = v
+	}
+	if il > 1.0 {
+		return 0, nil
+	}
+	return interp2(fDepths, fIL, fTable, z, math.Max(il, 0.2)), nil
+}
+
+// tableResistance returns R at the toe depth z for the installation
+// method.
+func tableResistance(l Layer, z float64, install string) (float64, error) {
+	st := soilType(l.SoilType)
+	if st == SoilClay {
+		il, err := clayIL(l)
+		if err != nil {
+			return 0, err
+		}
+		if il > 0.6 {
+			return 0, fmt.Errorf("toe in clay with IL above 0.6")
+		}
+		if install == InstallBored {
+			return interp2(rBoredDepths, rBoredIL, rBored, z, math.Max(il, 0)), nil
+		}
+		return interp2(rDepths, rClayIL, rClay, z, math.Max(il, 0)), nil
+	}
+	col, ok := sandColumn[st]
+	if !ok {
+		return 0, fmt.Errorf("unknown soil type %q", l.SoilType)
+	}
+	if install == InstallBored {
+		return 0, fmt.Errorf("bored pile toe in sand requires base_qb_kpa")
+	}
+	rows := make([]float64, len(rDepths))
+	for i := range rDepths {
+		rows[i] = rSand[i][col]
+	}
+	return interp1(rDepths, rows, z), nil
+}
+
+// interp1 interpolates linearly, holding the end values outside the table.
+func interp1(xs, ys []float64, x float64) float64 {
+	if x <= xs[0] {
+		return ys[0]
+	}
+	if x >= xs[len(xs)-1] {
+		return ys[len(ys)-1]
+	}
+	i := sort.SearchFloat64s(xs, x)
+	t := (x - xs[i-1]) / (xs[i] - xs[i-1])
+	return ys[i-1] + t*(ys[i]-ys[i-1])
+}
+
+// interp2 interpolates the table by row key x and column key y.
+func interp2(xs, ys []float64, table [][]float64, x, y float64) float64 {
+	col := make([]float64, len(xs))
+	for i, row := range table {
+		col[i] = interp1(ys, row, y)
+	}
+	return interp1(xs, col, x)
+}
+
+func layerAtDepth(layers []Layer, z float64) (Layer, bool) {
+	for _, l := range layers {
+		if z > l.FromDepthM && z <= l.ToDepthM {
+			return l, true
+		}
+	}
+	return Layer{}, false
+}
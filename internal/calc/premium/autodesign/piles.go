@@ -0,0 +1,182 @@
+package autodesign
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	piles "Vertex/internal/calc/piles"
+)
+
+// PileAutoInput sweeps square driven and round bored piles over the given
+// sections and lengths. Pile carries the soil, loads and method; its
+// geometry and toe resistance are replaced for every candidate. The head
+// stays at HeadDepthM.
+type PileAutoInput struct {
+	Pile            piles.Input `json:"pile"`
+	HeadDepthM      float64     `json:"head_depth_m"`
+	SquareSidesM    []float64   `json:"square_sides_m"`
+	RoundDiametersM []float64   `json:"round_diameters_m"`
+	LengthMinM      float64     `json:"length_min_m"`
+	LengthMaxM      float64     `json:"length_max_m"`
+	LengthStepM     float64     `json:"length_step_m"`
+}
+
+type PileCandidate struct {
+	PileType           string  `json:"pile_type"`
+	Installation       string  `json:"installation"`
+	SizeM              float64 `json:"size_m"`
+	LengthM            float64 `json:"length_m"`
+	PileCount          int     `json:"pile_count"`
+	ConcreteM3         float64 `json:"concrete_m3"`
+	DesignResistanceKN float64 `json:"design_resistance_kn"`
+	SettlementMM       float64 `json:"settlement_mm,omitempty"`
+}
+
+// PileDropped counts the candidates of one pile type dropped for the same
+// reason.
+type PileDropped struct {
+	PileType     string `json:"pile_type"`
+	Installation string `json:"installation"`
+	Reason       string `json:"reason"`
+	Count        int    `json:"count"`
+}
+
+type PileAutoResult struct {
+	Evaluated int             `json:"evaluated"`
+	Feasible  int             `json:"feasible"`
+	Pareto    []PileCandidate `json:"pareto"`
+	Dropped   []PileDropped   `json:"dropped,omitempty"`
+	Notes     string          `json:"notes"`
+}
+
+// maxPileCandidates bounds the sweep.
+const maxPileCandidates = 2000
+
+func Piles(in PileAutoInput) (PileAutoResult, error) {
+	if len(in.SquareSidesM) == 0 {
+		in.SquareSidesM = []float64{0.3, 0.35, 0.4}
+	}
+	if len(in.RoundDiametersM) == 0 {
+		in.RoundDiametersM = []float64{0.4, 0.5, 0.6, 0.8}
+	}
+	if in.LengthMinM <= 0 {
+		in.LengthMinM = 3
+	}
+	if in.LengthMaxM <= 0 {
+		in.LengthMaxM = 20
+	}
+	if in.LengthStepM <= 0 {
+		in.LengthStepM = 1
+	}
+	if in.LengthMaxM < in.LengthMinM {
+		return PileAutoResult{}, fmt.Errorf("invalid length range")
+	}
+	steps := int(math.Floor((in.LengthMaxM-in.LengthMinM)/in.LengthStepM+1e-9)) + 1
+	if steps*(len(in.SquareSidesM)+len(in.RoundDiametersM)) > maxPileCandidates {
+		return PileAutoResult{}, fmt.Errorf("too many candidates")
+	}
+
+	type option struct {
+		pileType, install string
+		sizes             []float64
+	}
+	options := []option{
+		{"square", piles.InstallDriven, in.SquareSidesM},
+		{"round", piles.InstallBored, in.RoundDiametersM},
+	}
+	var res PileAutoResult
+	var feasible []PileCandidate
+	drop := func(o option, reason string) {
+		for i, d := range res.Dropped {
+			if d.PileType == o.pileType && d.Reason == reason {
+				res.Dropped[i].Count++
+				return
+			}
+		}
+		res.Dropped = append(res.Dropped, PileDropped{PileType: o.pileType, Installation: o.install, Reason: reason, Count: 1})
+	}
+	for _, o := range options {
+		for _, size := range o.sizes {
+			for i := 0; i < steps; i++ {
+				l := in.LengthMinM + float64(i)*in.LengthStepM
+				p := in.Pile
+				p.PileType, p.Installation, p.LengthM = o.pileType, o.install, l
+				p.ToeDepthM = in.HeadDepthM + l
+				p.SideM, p.DiameterM = 0, 0
+				// A given qb belongs to one pile; each candidate takes its own
+				// from the tables or the sounding
+				p.BaseQbKPa = 0
+				area := math.Pi * size * size / 4
+				if o.pileType == "square" {
+					p.SideM = size
+					area = size * size
+				} else {
+					p.DiameterM = size
+				}
+				// The layout is what is being chosen
+				p.Group = nil
+				res.Evaluated++
+				r, err := piles.Calculate(p)
+				if err != nil {
+					drop(o, err.Error())
+					continue
+				}
+				if r.PileCount < 1 {
+					drop(o, "no design resistance")
+					continue
+				}
+				if r.SettlementLimitMM > 0 && !r.SettlementOK {
+					drop(o, "settlement over the limit")
+					continue
+				}
+				feasible = append(feasible, PileCandidate{
+					PileType:           o.pileType,
+					Installation:       o.install,
+					SizeM:              size,
+					LengthM:            l,
+					PileCount:          r.PileCount,
+					ConcreteM3:         float64(r.PileCount) * area * l,
+					DesignResistanceKN: r.DesignResistanceKN,
+					SettlementMM:       r.SettlementMM,
+				})
+			}
+		}
+	}
+	res.Feasible = len(feasible)
+	if len(feasible) == 0 {
+		res.Notes = "No feasible pile; see the dropped candidates."
+		return res, nil
+	}
+	res.Pareto = pareto(feasible)
+	res.Notes = "Pareto set of pile count, total concrete volume and pile length; each candidate is a full piles calculation."
+	if len(res.Dropped) > 0 {
+		res.Notes += " Some candidates were dropped; see the reasons."
+	}
+	return res, nil
+}
+
+// pareto keeps the candidates no other candidate beats on every one of
+// pile count, concrete volume and length, sorted by concrete volume.
+func pareto(cs []PileCandidate) []PileCandidate {
+	dominates := func(a, b PileCandidate) bool {
+		le := a.PileCount <= b.PileCount && a.ConcreteM3 <= b.ConcreteM3+1e-9 && a.LengthM <= b.LengthM+1e-9
+		lt := a.PileCount < b.PileCount || a.ConcreteM3 < b.ConcreteM3-1e-9 || a.LengthM < b.LengthM-1e-9
+		return le && lt
+	}
+	var out []PileCandidate
+	for i, c := range cs {
+		keep := true
+		for j, o := range cs {
+			if i != j && dominates(o, c) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ConcreteM3 < out[j].ConcreteM3 })
+	return out
+}
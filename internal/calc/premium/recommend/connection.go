@@ -0,0 +1,170 @@
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	bolts "Vertex/internal/calc/bolts"
+	joints "Vertex/internal/calc/joints"
+)
+
+type ConnectionInput struct {
+	ShearKN        float64        `json:"shear_kn"`
+	TensionKN      float64        `json:"tension_kn"`
+	EccentricityMM float64        `json:"eccentricity_mm"`
+	Thickness1MM   float64        `json:"thickness1_mm"`
+	Thickness2MM   float64        `json:"thickness2_mm"`
+	PlyRunMPa      float64        `json:"ply_run_mpa"`
+	ShearPlanes    int            `json:"shear_planes"`
+	MaxBolts       int            `json:"max_bolts"`
+	WeldLengthMM   float64        `json:"weld_length_mm"`
+	Electrode      string         `json:"electrode"`
+	Process        joints.Process `json:"process"`
+}
+
+type BoltOption struct {
+	Class       string       `json:"class"`
+	DiameterMM  float64      `json:"diameter_mm"`
+	Count       int          `json:"count"`
+	Rows        int          `json:"rows"`
+	Columns     int          `json:"columns"`
+	HoleMM      float64      `json:"hole_mm"`
+	PitchMM     float64      `json:"pitch_mm"`
+	GaugeMM     float64      `json:"gauge_mm"`
+	EndMM       float64      `json:"end_mm"`
+	EdgeMM      float64      `json:"edge_mm"`
+	Layout      []bolts.Bolt `json:"layout"`
+	Utilization float64      `json:"utilization"`
+	Cost        float64      `json:"cost"`
+}
+
+type ConnectionResult struct {
+	Welds []WeldOption `json:"welds"`
+	Bolts []BoltOption `json:"bolts"`
+	Notes string       `json:"notes"`
+}
+
+var boltDiameters = []float64{16, 20, 24, 27, 30}
+
+// Relative price of one bolt set of 20 mm diameter by class.
+var boltClassCost = map[string]float64{
+	"5.8":  1.0,
+	"8.8":  1.6,
+	"10.9": 2.4,
+}
+
+const maxBoltOptions = 10
+
+func Connection(in ConnectionInput) (ConnectionResult, error) {
+	if in.ShearKN <= 0 && in.TensionKN <= 0 {
+		return ConnectionResult{}, fmt.Errorf("invalid input")
+	}
+	if in.Thickness1MM <= 0 && in.Thickness2MM <= 0 {
+		return ConnectionResult{}, fmt.Errorf("invalid thickness")
+	}
+	if in.MaxBolts <= 0 {
+		in.MaxBolts = 12
+	}
+	tply := math.Min(in.Thickness1MM, in.Thickness2MM)
+	if tply <= 0 {
+		tply = math.Max(in.Thickness1MM, in.Thickness2MM)
+	}
+
+	var out ConnectionResult
+	if in.WeldLengthMM > 0 && in.ShearKN > 0 {
+		w, err := WeldSize(WeldRecommendInput{
+			ShearKN:      in.ShearKN,
+			WeldLengthMM: in.WeldLengthMM,
+			Thickness1MM: in.Thickness1MM,
+			Thickness2MM: in.Thickness2MM,
+			Electrode:    in.Electrode,
+			Process:      in.Process,
+			RunMPa:       in.PlyRunMPa,
+		})
+		if err == nil {
+			out.Welds = w.Options
+		}
+	}
+
+	for _, cls := range []string{"5.8", "8.8", "10.9"} {
+		for _, d := range boltDiameters {
+			if opt, ok := smallestBoltGroup(in, cls, d, tply); ok {
+				out.Bolts = append(out.Bolts, opt)
+			}
+		}
+	}
+	sort.Slice(out.Bolts, func(i, j int) bool { return out.Bolts[i].Cost < out.Bolts[j].Cost })
+	if len(out.Bolts) > maxBoltOptions {
+		out.Bolts = out.Bolts[:maxBoltOptions]
+	}
+	if len(out.Welds) == 0 && len(out.Bolts) == 0 {
+		return ConnectionResult{}, fmt.Errorf("no feasible connection")
+	}
+	out.Notes = "Options ranked by relative cost. Bolt spacing per SP16 table 40 rounded up to 5 mm; bearing-type bolts."
+	return out, nil
+}
+
+// smallestBoltGroup finds the fewest bolts of a given class and diameter in
+// one or two columns that carry the load.
+func smallestBoltGroup(in ConnectionInput, cls string, d, tply float64) (BoltOption, bool) {
+	d0 := d + 3
+	pitch := roundUp5(3 * d0)
+	end := roundUp5(2 * d0)
+	edge := roundUp5(1.5 * d0)
+	for n := 2; n <= in.MaxBolts; n++ {
+		for cols := 1; cols <= 2; cols++ {
+			if n%cols != 0 {
+				continue
+			}
+			rows := n / cols
+			gauge := 0.0
+			if cols == 2 {
+				gauge = pitch
+			}
+			layout := make([]bolts.Bolt, 0, n)
+			for r := 0; r < rows; r++ {
+				for c := 0; c < cols; c++ {
+					layout = append(layout, bolts.Bolt{
+						XMM: float64(c)*gauge - gauge/2*float64(cols-1),
+						YMM: float64(r)*pitch - pitch/2*float64(rows-1),
+					})
+				}
+			}
+			res, err := bolts.Calculate(bolts.Input{
+				Class:          cls,
+				DiameterMM:     d,
+				ShearPlanes:    in.ShearPlanes,
+				PlyThicknessMM: tply,
+				PlyRunMPa:      in.PlyRunMPa,
+				Bolts:          layout,
+				ShearYKN:       in.ShearKN,
+				LoadXMM:        in.EccentricityMM,
+				TensionKN:      in.TensionKN,
+			})
+			if err != nil || !res.OK {
+				continue
+			}
+			return BoltOption{
+				Class:       cls,
+				DiameterMM:  d,
+				Count:       n,
+				Rows:        rows,
+				Columns:     cols,
+				HoleMM:      d0,
+				PitchMM:     pitch,
+				GaugeMM:     gauge,
+				EndMM:       end,
+				EdgeMM:      edge,
+				Layout:      layout,
+				Utilization: res.Utilization,
+				Cost:        float64(n) * (boltClassCost[cls]*math.Pow(d/20, 2) + 1),
+			}, true
+		}
+	}
+	return BoltOption{}, false
+}
+
+func roundUp5(v float64) float64 {
+	return math.Ceil(v/5) * 5
+}
@@ -1,36 +1,131 @@
 package recommend
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	joints "Vertex/internal/calc/joints"
+)
 
 type WeldRecommendInput struct {
-	ShearKN      float64 `json:"shear_kn"`
-	WeldLengthMM float64 `json:"weld_length_mm"`
-	FvwMPa       float64 `json:"fvw_mpa"`
-	GammaM       float64 `json:"gamma_m"`
+	ShearKN      float64        `json:"shear_kn"`
+	WeldLengthMM float64        `json:"weld_length_mm"`
+	Thickness1MM float64        `json:"thickness1_mm"`
+	Thickness2MM float64        `json:"thickness2_mm"`
+	Electrode    string         `json:"electrode"`
+	Process      joints.Process `json:"process"`
+	RunMPa       float64        `json:"run_mpa"`
+}
+
+type WeldOption struct {
+	LegMM       float64 `json:"leg_mm"`
+	LengthMM    float64 `json:"length_mm"`
+	CapacityKN  float64 `json:"capacity_kn"`
+	Utilization float64 `json:"utilization"`
+	Cost        float64 `json:"cost"`
 }
 
 type WeldRecommendResult struct {
-	RequiredSizeMM float64 `json:"required_size_mm"`
-	Notes          string  `json:"notes"`
+	RequiredSizeMM float64      `json:"required_size_mm"`
+	MinSizeMM      float64      `json:"min_size_mm"`
+	MaxSizeMM      float64      `json:"max_size_mm"`
+	Options        []WeldOption `json:"options"`
+	Notes          string       `json:"notes"`
+}
+
+// Standard fillet weld legs, mm.
+var weldLegs = []float64{4, 5, 6, 7, 8, 9, 10, 12, 14, 16, 18, 20}
+
+// minLeg returns kf,min by the thicker welded element, SP16 table 38
+// (manual welding, Ryn up to 430 MPa). Mechanized and automatic welding
+// allow one millimetre less.
+func minLeg(t float64, p joints.Process) float64 {
+	var kf float64
+	switch {
+	case t <= 5:
+		kf = 4
+	case t <= 10:
+		kf = 5
+	case t <= 16:
+		kf = 6
+	case t <= 22:
+		kf = 7
+	case t <= 32:
+		kf = 8
+	case t <= 40:
+		kf = 9
+	default:
+		kf = 10
+	}
+	if p == joints.ProcessMechanized || p == joints.ProcessAutomatic {
+		kf--
+	}
+	return kf
 }
 
 func WeldSize(in WeldRecommendInput) (WeldRecommendResult, error) {
 	if in.ShearKN <= 0 || in.WeldLengthMM <= 0 {
 		return WeldRecommendResult{}, fmt.Errorf("invalid input")
 	}
-	if in.FvwMPa <= 0 {
-		in.FvwMPa = 180
+	tmax := math.Max(in.Thickness1MM, in.Thickness2MM)
+	tmin := math.Min(in.Thickness1MM, in.Thickness2MM)
+	if tmin <= 0 {
+		tmin = tmax
+	}
+	kmin := 4.0
+	kmax := weldLegs[len(weldLegs)-1]
+	if tmax > 0 {
+		kmin = minLeg(tmax, in.Process)
+		// kf,max = 1.2 t of the thinner element
+		kmax = 1.2 * tmin
+	}
+	if kmin > kmax {
+		return WeldRecommendResult{}, fmt.Errorf("plates too thin for a fillet weld")
 	}
-	if in.GammaM <= 0 {
-		in.GammaM = 1.25
+
+	var opts []WeldOption
+	for _, kf := range weldLegs {
+		if kf < kmin || kf > kmax {
+			continue
+		}
+		res, err := joints.Calculate(joints.Input{
+			WeldSizeMM:   kf,
+			WeldLengthMM: in.WeldLengthMM,
+			Electrode:    in.Electrode,
+			Process:      in.Process,
+			RunMPa:       in.RunMPa,
+			ShearKN:      in.ShearKN,
+		})
+		if err != nil {
+			return WeldRecommendResult{}, err
+		}
+		if !res.OK {
+			continue
+		}
+		opts = append(opts, WeldOption{
+			LegMM:       kf,
+			LengthMM:    in.WeldLengthMM,
+			CapacityKN:  res.CapacityKN,
+			Utilization: res.Utilization,
+			Cost:        weldCost(kf, in.WeldLengthMM),
+		})
 	}
-	// V = 0.7*s*L*fvw/gamma /1000
-	s := (in.ShearKN * 1000.0 * in.GammaM) / (0.7 * in.WeldLengthMM * in.FvwMPa)
-	if s < 3 {
-		s = 3
+	if len(opts) == 0 {
+		return WeldRecommendResult{}, fmt.Errorf("no fillet weld fits within kf,max")
 	}
+	sort.Slice(opts, func(i, j int) bool { return opts[i].Cost < opts[j].Cost })
 	return WeldRecommendResult{
-		RequiredSizeMM: s,
-		Notes:          "Recommended fillet weld size for shear.",
+		RequiredSizeMM: opts[0].LegMM,
+		MinSizeMM:      kmin,
+		MaxSizeMM:      kmax,
+		Options:        opts,
+		Notes:          "Fillet weld legs from the SP16 kf,min/kf,max limits checked on metal and fusion boundary sections.",
 	}, nil
 }
+
+// weldCost is the deposited metal volume in cm3, a proxy for labour and
+// consumables.
+func weldCost(kf, length float64) float64 {
+	return kf * kf / 2 * length / 1000.0
+}
@@ -0,0 +1,201 @@
+package seismic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const gravity = 9.81
+
+// Storey is listed from the ground up. MassT, when given, overrides
+// WeightKN; StiffnessKNm is the lateral storey stiffness for the Rayleigh
+// period.
+type Storey struct {
+	Name         string  `json:"name"`
+	HeightM      float64 `json:"height_m"`
+	MassT        float64 `json:"mass_t"`
+	WeightKN     float64 `json:"weight_kn"`
+	StiffnessKNm float64 `json:"stiffness_kn_m"`
+}
+
+// Input of the equivalent lateral force method of SP14. Intensity is the
+// design site intensity (7, 8 or 9). PeriodS, when given, is used as is;
+// otherwise the Rayleigh period is used when every storey has a stiffness
+// and T = PeriodCoeff·n otherwise.
+type Input struct {
+	Storeys      []Storey `json:"storeys"`
+	Intensity    int      `json:"intensity"`
+	SoilCategory string   `json:"soil_category"`
+	K0           float64  `json:"k0"`
+	K1           float64  `json:"k1"`
+	KPsi         float64  `json:"k_psi"`
+	PeriodS      float64  `json:"period_s"`
+	PeriodCoeff  float64  `json:"period_coeff"`
+}
+
+type StoreyForce struct {
+	Name          string  `json:"name"`
+	LevelM        float64 `json:"level_m"`
+	MassT         float64 `json:"mass_t"`
+	Eta           float64 `json:"eta"`
+	ForceKN       float64 `json:"force_kn"`
+	ShearKN       float64 `json:"shear_kn"`
+	OverturningKN float64 `json:"overturning_knm"`
+}
+
+type Result struct {
+	PeriodS       float64       `json:"period_s"`
+	PeriodMethod  string        `json:"period_method"`
+	AccelMS2      float64       `json:"accel_m_s2"`
+	Beta          float64       `json:"beta"`
+	Storeys       []StoreyForce `json:"storeys"`
+	BaseShearKN   float64       `json:"base_shear_kn"`
+	BaseMomentKNm float64       `json:"base_moment_knm"`
+	Notes         string        `json:"notes"`
+}
+
+// Ground acceleration A by intensity, SP14 5.5 (m/s2).
+var accel = map[int]float64{7: 1.0, 8: 2.0, 9: 4.0}
+
+func Calculate(in Input) (Result, error) {
+	if len(in.Storeys) == 0 {
+		return Result{}, fmt.Errorf("no storeys")
+	}
+	a, ok := accel[in.Intensity]
+	if !ok {
+		return Result{}, fmt.Errorf("invalid intensity")
+	}
+	corner, err := cornerPeriod(in.SoilCategory)
+	if err != nil {
+		return Result{}, err
+	}
+	if in.K0 <= 0 {
+		in.K0 = 1.0
+	}
+	if in.K1 <= 0 {
+		in.K1 = 1.0
+	}
+	if in.KPsi <= 0 {
+		in.KPsi = 1.0
+	}
+	if in.PeriodCoeff <= 0 {
+		in.PeriodCoeff = 0.1
+	}
+
+	n := len(in.Storeys)
+	mass := make([]float64, n)
+	level := make([]float64, n)
+	z := 0.0
+	rayleigh := true
+	for i, s := range in.Storeys {
+		if s.HeightM <= 0 {
+			return Result{}, fmt.Errorf("invalid storey height")
+		}
+		mass[i] = s.MassT
+		if mass[i] <= 0 {
+			mass[i] = s.WeightKN / gravity
+		}
+		if mass[i] <= 0 {
+			return Result{}, fmt.Errorf("invalid storey mass")
+		}
+		z += s.HeightM
+		level[i] = z
+		if s.StiffnessKNm <= 0 {
+			rayleigh = false
+		}
+	}
+
+	res := Result{AccelMS2: a}
+	// First mode shape: Rayleigh displacements under storey weights or the
+	// straight line of SP14 (5.10)
+	shape := append([]float64{}, level...)
+	if rayleigh {
+		shape = rayleighShape(in.Storeys, mass)
+	}
+	switch {
+	case in.PeriodS > 0:
+		res.PeriodS, res.PeriodMethod = in.PeriodS, "given"
+	case rayleigh:
+		num, den := 0.0, 0.0
+		for i, u := range shape {
+			num += mass[i] * gravity * u * u
+			den += mass[i] * gravity * u
+		}
+		res.PeriodS = 2 * math.Pi * math.Sqrt(num/(gravity*den))
+		res.PeriodMethod = "Rayleigh"
+	default:
+		res.PeriodS = in.PeriodCoeff * float64(n)
+		res.PeriodMethod = "simplified"
+	}
+	res.Beta = beta(res.PeriodS, corner)
+
+	sumMX, sumMX2 := 0.0, 0.0
+	for i := range shape {
+		sumMX += mass[i] * shape[i]
+		sumMX2 += mass[i] * shape[i] * shape[i]
+	}
+	forces := make([]float64, n)
+	for i := range in.Storeys {
+		// S = K0·K1·m·A·β·Kψ·η, SP14 (5.1), (5.2)
+		eta := shape[i] * sumMX / sumMX2
+		forces[i] = in.K0 * in.K1 * mass[i] * a * res.Beta * in.KPsi * eta
+		res.Storeys = append(res.Storeys, StoreyForce{
+			Name:    in.Storeys[i].Name,
+			LevelM:  level[i],
+			MassT:   mass[i],
+			Eta:     eta,
+			ForceKN: forces[i],
+		})
+	}
+	for i := range res.Storeys {
+		base := level[i] - in.Storeys[i].HeightM
+		for j := i; j < n; j++ {
+			res.Storeys[i].ShearKN += forces[j]
+			res.Storeys[i].OverturningKN += forces[j] * (level[j] - base)
+		}
+	}
+	res.BaseShearKN = res.Storeys[0].ShearKN
+	res.BaseMomentKNm = res.Storeys[0].OverturningKN
+	res.Notes = "Equivalent lateral force method per SP14 for the first mode; storey masses from the special combination are the user's input."
+	return res, nil
+}
+
+// cornerPeriod is the end of the β plateau: 0.4 s for soil categories I
+// and II, 0.8 s for III and IV.
+func cornerPeriod(cat string) (float64, error) {
+	switch strings.ToUpper(strings.TrimSpace(cat)) {
+	case "I", "II", "":
+		return 0.4, nil
+	case "III", "IV":
+		return 0.8, nil
+	}
+	return 0, fmt.Errorf("invalid soil category")
+}
+
+// beta is the dynamic coefficient of SP14 (5.5), not less than 0.8.
+func beta(t, corner float64) float64 {
+	switch {
+	case t <= 0.1:
+		return 1 + 15*t
+	case t <= corner:
+		return 2.5
+	}
+	return math.Max(2.5*math.Sqrt(corner/t), 0.8)
+}
+
+// rayleighShape returns the storey displacements (m) under the storey
+// weights applied laterally.
+func rayleighShape(st []Storey, mass []float64) []float64 {
+	u := make([]float64, len(st))
+	disp := 0.0
+	for i := range st {
+		shear := 0.0
+		for j := i; j < len(st); j++ {
+			shear += mass[j] * gravity
+		}
+		disp += shear / st[i].StiffnessKNm
+		u[i] = disp
+	}
+	return u
+}
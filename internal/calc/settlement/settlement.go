@@ -0,0 +1,129 @@
+package settlement
+
+import (
+	"fmt"
+	"math"
+)
+
+// Layer is a soil layer by depth from the ground surface (m).
+type Layer struct {
+	TopM      float64
+	BottomM   float64
+	GammaKNm3 float64
+	EMPa      float64
+}
+
+// Footing is a rectangular base of B x L at DepthM with the mean pressure
+// under it (kPa).
+type Footing struct {
+	BM          float64
+	LM          float64
+	DepthM      float64
+	PressureKPa float64
+}
+
+type Sublayer struct {
+	TopM       float64 `json:"top_m"`
+	BottomM    float64 `json:"bottom_m"`
+	SigmaZgKPa float64 `json:"sigma_zg_kpa"`
+	SigmaZpKPa float64 `json:"sigma_zp_kpa"`
+	EMPa       float64 `json:"e_mpa"`
+	SM         float64 `json:"s_m"`
+}
+
+type Result struct {
+	SettlementM        float64    `json:"settlement_m"`
+	CompressibleDepthM float64    `json:"compressible_depth_m"`
+	Truncated          bool       `json:"truncated"`
+	Sublayers          []Sublayer `json:"sublayers"`
+}
+
+// beta is the dimensionless factor of the layer summation, SP22 (5.16).
+const beta = 0.8
+
+// Calculate sums the settlement of sublayers no thicker than 0.4·B under
+// the centre of the footing down to the depth where the additional stress
+// falls to ratio·σzg (0.5 per SP22 5.6.41). Truncated is set when the
+// layers end first.
+func Calculate(layers []Layer, f Footing, ratio float64) (Result, error) {
+	if f.BM <= 0 || f.LM <= 0 || len(layers) == 0 {
+		return Result{}, fmt.Errorf("invalid input")
+	}
+	if ratio <= 0 {
+		ratio = 0.5
+	}
+	bottom := 0.0
+	for _, l := range layers {
+		if l.BottomM <= l.TopM || l.EMPa <= 0 {
+			return Result{}, fmt.Errorf("invalid soil layer")
+		}
+		bottom = math.Max(bottom, l.BottomM)
+	}
+	sigmaG := func(z float64) float64 {
+		s := 0.0
+		for _, l := range layers {
+			s += l.GammaKNm3 * math.Max(0, math.Min(z, l.BottomM)-l.TopM)
+		}
+		return s
+	}
+	p0 := f.PressureKPa - sigmaG(f.DepthM)
+	res := Result{Truncated: true}
+	if p0 <= 0 {
+		res.Truncated = false
+		res.CompressibleDepthM = f.DepthM
+		return res, nil
+	}
+	h := 0.4 * f.BM
+	for z := f.DepthM; z < bottom-1e-9; {
+		l, ok := layerAt(layers, z)
+		if !ok {
+			break
+		}
+		z1 := math.Min(z+h, l.BottomM)
+		mid := (z + z1) / 2
+		szp := p0 * CentreFactor(f.BM, f.LM, mid-f.DepthM)
+		szg := sigmaG(mid)
+		sl := Sublayer{
+			TopM:       z,
+			BottomM:    z1,
+			SigmaZgKPa: szg,
+			SigmaZpKPa: szp,
+			EMPa:       l.EMPa,
+			SM:         beta * szp * (z1 - z) / (l.EMPa * 1000),
+		}
+		res.Sublayers = append(res.Sublayers, sl)
+		res.SettlementM += sl.SM
+		z = z1
+		if szp <= ratio*szg {
+			res.CompressibleDepthM = z1
+			res.Truncated = false
+			break
+		}
+	}
+	if res.Truncated {
+		res.CompressibleDepthM = bottom
+	}
+	return res, nil
+}
+
+func layerAt(layers []Layer, z float64) (Layer, bool) {
+	for _, l := range layers {
+		if z >= l.TopM-1e-9 && z < l.BottomM-1e-9 {
+			return l, true
+		}
+	}
+	return Layer{}, false
+}
+
+// CentreFactor is α, the ratio of the vertical stress under the centre of
+// a uniformly loaded B x L rectangle at depth z to the load, from the
+// Boussinesq corner solution.
+func CentreFactor(b, l, z float64) float64 {
+	if z <= 0 {
+		return 1
+	}
+	a, c := l/2, b/2
+	r := math.Sqrt(a*a + c*c + z*z)
+	corner := (a*c*z*(a*a+c*c+2*z*z)/((a*a+z*z)*(c*c+z*z)*r) + math.Atan(a*c/(z*r))) / (2 * math.Pi)
+	return 4 * corner
+}
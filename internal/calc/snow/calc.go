@@ -0,0 +1,247 @@
+package snow
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	loads "Vertex/internal/calc/loads"
+)
+
+type Roof string
+
+const (
+	RoofSingle     Roof = "single"
+	RoofDouble     Roof = "double"
+	RoofVaulted    Roof = "vaulted"
+	RoofMultiLevel Roof = "multilevel"
+)
+
+// Input describes the roof in section across its span. SgKPa, when given,
+// overrides the region. For a multi-level roof SpanM is the lower roof, x
+// is measured from the step and UpperSpanM is the upper roof.
+type Input struct {
+	Region      string  `json:"region"`
+	SgKPa       float64 `json:"sg_kpa"`
+	Roof        Roof    `json:"roof"`
+	SpanM       float64 `json:"span_m"`
+	SlopeDeg    float64 `json:"slope_deg"`
+	RiseM       float64 `json:"rise_m"`
+	UpperSpanM  float64 `json:"upper_span_m"`
+	HeightDiffM float64 `json:"height_diff_m"`
+	Ce          float64 `json:"ce"`
+	Ct          float64 `json:"ct"`
+	GammaF      float64 `json:"gamma_f"`
+	// TributaryAreaM2 converts the governing pressure into the load cases
+	// for the combination engine; 1 m2 when not given.
+	TributaryAreaM2 float64 `json:"tributary_area_m2"`
+}
+
+type MuPoint struct {
+	XM float64 `json:"x_m"`
+	Mu float64 `json:"mu"`
+}
+
+type Zone struct {
+	Name      string  `json:"name"`
+	FromM     float64 `json:"from_m"`
+	ToM       float64 `json:"to_m"`
+	Mu        float64 `json:"mu"`
+	S0KPa     float64 `json:"s0_kpa"`
+	DesignKPa float64 `json:"design_kpa"`
+	LongKPa   float64 `json:"long_kpa"`
+}
+
+type Variant struct {
+	Name   string    `json:"name"`
+	Points []MuPoint `json:"points"`
+	Zones  []Zone    `json:"zones"`
+}
+
+type Result struct {
+	SgKPa        float64          `json:"sg_kpa"`
+	Ce           float64          `json:"ce"`
+	Ct           float64          `json:"ct"`
+	Variants     []Variant        `json:"variants"`
+	MaxDesignKPa float64          `json:"max_design_kpa"`
+	Cases        []loads.LoadCase `json:"cases"`
+	Notes        string           `json:"notes"`
+}
+
+// Snow load on the ground Sg by region, SP20 table 10.1 (kPa).
+var regionSg = map[string]float64{
+	"I": 0.5, "II": 1.0, "III": 1.5, "IV": 2.0,
+	"V": 2.5, "VI": 3.0, "VII": 3.5, "VIII": 4.0,
+}
+
+const pointsPerSpan = 20
+
+func Calculate(in Input) (Result, error) {
+	sg := in.SgKPa
+	if sg <= 0 {
+		var ok bool
+		if sg, ok = regionSg[strings.ToUpper(strings.TrimSpace(in.Region))]; !ok {
+			return Result{}, fmt.Errorf("invalid snow region")
+		}
+	}
+	if in.SpanM <= 0 {
+		return Result{}, fmt.Errorf("invalid span")
+	}
+	if in.Ce <= 0 {
+		in.Ce = 1.0
+	}
+	if in.Ct <= 0 {
+		in.Ct = 1.0
+	}
+	if in.GammaF <= 0 {
+		in.GammaF = 1.4
+	}
+	if in.TributaryAreaM2 <= 0 {
+		in.TributaryAreaM2 = 1
+	}
+
+	var variants []Variant
+	switch in.Roof {
+	case RoofSingle, "":
+		mu := slopeMu(in.SlopeDeg)
+		variants = append(variants, uniform("1", in.SpanM, []string{"roof"}, []float64{mu}))
+	case RoofDouble:
+		mu := slopeMu(in.SlopeDeg)
+		variants = append(variants, uniform("1", in.SpanM, []string{"left slope", "right slope"}, []float64{mu, mu}))
+		if in.SlopeDeg >= 15 && in.SlopeDeg <= 40 {
+			variants = append(variants, uniform("2", in.SpanM, []string{"windward slope", "leeward slope"}, []float64{0.75 * mu, 1.25 * mu}))
+		}
+	case RoofVaulted:
+		if in.RiseM <= 0 || in.RiseM > in.SpanM/2 {
+			return Result{}, fmt.Errorf("invalid rise")
+		}
+		variants = vaulted(in.SpanM, in.RiseM)
+	case RoofMultiLevel:
+		if in.HeightDiffM <= 0 || in.UpperSpanM < 0 {
+			return Result{}, fmt.Errorf("invalid roof step")
+		}
+		variants = append(variants, drift(in.SpanM, in.UpperSpanM, in.HeightDiffM, sg))
+	default:
+		return Result{}, fmt.Errorf("invalid roof shape")
+	}
+
+	res := Result{SgKPa: sg, Ce: in.Ce, Ct: in.Ct}
+	for vi := range variants {
+		v := &variants[vi]
+		peak := 0.0
+		for zi := range v.Zones {
+			z := &v.Zones[zi]
+			// S0 = ce·ct·μ·Sg, SP20 (10.1); the reduced value is 0.5·S0
+			z.S0KPa = in.Ce * in.Ct * z.Mu * sg
+			z.DesignKPa = in.GammaF * z.S0KPa
+			z.LongKPa = 0.5 * z.S0KPa
+			peak = math.Max(peak, z.S0KPa)
+		}
+		res.MaxDesignKPa = math.Max(res.MaxDesignKPa, in.GammaF*peak)
+		res.Cases = append(res.Cases, loads.LoadCase{
+			Name:    "Snow " + v.Name,
+			Type:    loads.CaseSnow,
+			ValueKN: peak * in.TributaryAreaM2,
+			GammaF:  in.GammaF,
+			Group:   "snow",
+		})
+	}
+	res.Variants = variants
+	res.Notes = "Snow load per SP20 section 10 and appendix B; load cases carry the peak zone of each variant."
+	return res, nil
+}
+
+// slopeMu is μ for a pitched roof, SP20 B.1: 1 up to 30°, 0 from 60°.
+func slopeMu(deg float64) float64 {
+	switch {
+	case deg <= 30:
+		return 1
+	case deg >= 60:
+		return 0
+	}
+	return (60 - deg) / 30
+}
+
+// uniform splits the span into equal zones of constant μ.
+func uniform(name string, span float64, zones []string, mu []float64) Variant {
+	v := Variant{Name: name}
+	w := span / float64(len(zones))
+	for i, z := range zones {
+		x0 := float64(i) * w
+		v.Zones = append(v.Zones, Zone{Name: z, FromM: x0, ToM: x0 + w, Mu: mu[i]})
+		v.Points = append(v.Points, MuPoint{XM: x0, Mu: mu[i]}, MuPoint{XM: x0 + w, Mu: mu[i]})
+	}
+	return v
+}
+
+// vaulted gives μ1 = cos 1.8α over the whole roof and μ2 = 2.4 sin 1.4α on
+// the leeward half, SP20 B.3, with α the local slope of a circular arc.
+func vaulted(span, rise float64) []Variant {
+	r := (span*span/4 + rise*rise) / (2 * rise)
+	slope := func(x float64) float64 {
+		return math.Asin(math.Min(math.Abs(x-span/2)/r, 1))
+	}
+	mu1 := func(x float64) float64 {
+		return math.Max(math.Cos(1.8*slope(x)), 0)
+	}
+	mu2 := func(x float64) float64 {
+		if x < span/2 {
+			return 0
+		}
+		return math.Max(2.4*math.Sin(1.4*slope(x)), 0)
+	}
+	return []Variant{
+		sampled("1", span, mu1, []string{"quarter 1", "quarter 2", "quarter 3", "quarter 4"}),
+		sampled("2", span, mu2, []string{"quarter 1", "quarter 2", "quarter 3", "quarter 4"}),
+	}
+}
+
+// drift gives the snow bag at a roof step, SP20 B.8: μ = 1 + (m1·l1 +
+// m2·l2)/h at the step, limited by 2h/Sg and 4, falling to 1 over
+// b = 2h (5..16 m).
+func drift(lower, upper, h, sg float64) Variant {
+	const m1, m2 = 0.5, 0.5
+	mu := 1 + (m1*upper+m2*lower)/h
+	mu = math.Min(mu, math.Min(2*h/sg, 4))
+	mu = math.Max(mu, 1)
+	b := math.Min(math.Max(2*h, 5), 16)
+	b = math.Min(b, lower)
+	f := func(x float64) float64 {
+		if x >= b {
+			return 1
+		}
+		return mu - (mu-1)*x/b
+	}
+	v := Variant{Name: "1"}
+	for i := 0; i <= pointsPerSpan; i++ {
+		x := lower * float64(i) / pointsPerSpan
+		v.Points = append(v.Points, MuPoint{XM: x, Mu: f(x)})
+	}
+	v.Zones = append(v.Zones, Zone{Name: "drift", FromM: 0, ToM: b, Mu: mu})
+	if b < lower {
+		v.Zones = append(v.Zones, Zone{Name: "lower roof", FromM: b, ToM: lower, Mu: 1})
+	}
+	return v
+}
+
+// sampled tabulates μ along the span and gives each equal zone the largest
+// value within it.
+func sampled(name string, span float64, mu func(float64) float64, zones []string) Variant {
+	v := Variant{Name: name}
+	for i := 0; i <= pointsPerSpan; i++ {
+		x := span * float64(i) / pointsPerSpan
+		v.Points = append(v.Points, MuPoint{XM: x, Mu: mu(x)})
+	}
+	w := span / float64(len(zones))
+	for i, z := range zones {
+		x0, x1 := float64(i)*w, float64(i+1)*w
+		peak := 0.0
+		for _, p := range v.Points {
+			if p.XM >= x0-1e-9 && p.XM <= x1+1e-9 {
+				peak = math.Max(peak, p.Mu)
+			}
+		}
+		v.Zones = append(v.Zones, Zone{Name: z, FromM: x0, ToM: x1, Mu: peak})
+	}
+	return v
+}
@@ -0,0 +1,198 @@
+package takedown
+
+import (
+	"fmt"
+	"math"
+
+	loads "Vertex/internal/calc/loads"
+)
+
+type Layer struct {
+	Name        string  `json:"name"`
+	ThicknessMM float64 `json:"thickness_mm"`
+	DensityKNm3 float64 `json:"density_kn_m3"`
+	GammaF      float64 `json:"gamma_f"`
+}
+
+// BuildUp is a floor or roof: its layers, extra dead load (partitions,
+// services), the occupancy from the live load library and roof snow.
+type BuildUp struct {
+	Name       string  `json:"name"`
+	Layers     []Layer `json:"layers"`
+	ExtraKPa   float64 `json:"extra_kpa"`
+	ExtraGamma float64 `json:"extra_gamma_f"`
+	Occupancy  string  `json:"occupancy"`
+	SnowKPa    float64 `json:"snow_kpa"`
+}
+
+// Storey carries the build-up at its top. Storeys are listed from the roof
+// down.
+type Storey struct {
+	Name    string `json:"name"`
+	BuildUp string `json:"build_up"`
+}
+
+// Element is a column or a wall. AreaM2 is the tributary area per floor;
+// Areas overrides it storey by storey. For walls LengthM gives loads per
+// metre as well.
+type Element struct {
+	Name         string    `json:"name"`
+	Kind         string    `json:"kind"`
+	AreaM2       float64   `json:"area_m2"`
+	Areas        []float64 `json:"areas"`
+	LengthM      float64   `json:"length_m"`
+	SelfWeightKN float64   `json:"self_weight_kn"`
+}
+
+type Input struct {
+	BuildUps []BuildUp `json:"build_ups"`
+	Storeys  []Storey  `json:"storeys"`
+	Elements []Element `json:"elements"`
+}
+
+// Level is the axial force at the bottom of a storey. Field names follow
+// the piles input and LoadKN the column-SP input.
+type Level struct {
+	Storey       string  `json:"storey"`
+	LoadGKN      float64 `json:"load_g_kn"`
+	LoadQLongKN  float64 `json:"load_q_long_kn"`
+	LoadQShortKN float64 `json:"load_q_short_kn"`
+	SnowKN       float64 `json:"snow_kn"`
+	LoadKN       float64 `json:"load_kn"`
+	LoadKNm      float64 `json:"load_kn_m,omitempty"`
+	Formula      string  `json:"formula"`
+}
+
+type ElementResult struct {
+	Name   string  `json:"name"`
+	Kind   string  `json:"kind"`
+	Levels []Level `json:"levels"`
+}
+
+type Result struct {
+	Elements []ElementResult `json:"elements"`
+	Notes    string          `json:"notes"`
+}
+
+type floor struct {
+	g, gd     float64 // kPa, normative and design
+	occupancy string
+	snow      float64
+}
+
+func Calculate(in Input) (Result, error) {
+	if len(in.Storeys) == 0 || len(in.Elements) == 0 {
+		return Result{}, fmt.Errorf("invalid input")
+	}
+	floors := map[string]floor{}
+	for _, b := range in.BuildUps {
+		f := floor{occupancy: b.Occupancy, snow: b.SnowKPa}
+		for _, l := range b.Layers {
+			if l.ThicknessMM < 0 || l.DensityKNm3 < 0 {
+				return Result{}, fmt.Errorf("invalid layer in %s", b.Name)
+			}
+			gf := l.GammaF
+			if gf <= 0 {
+				gf = 1.1
+			}
+			g := l.ThicknessMM / 1000 * l.DensityKNm3
+			f.g += g
+			f.gd += gf * g
+		}
+		gf := b.ExtraGamma
+		if gf <= 0 {
+			gf = 1.1
+		}
+		f.g += b.ExtraKPa
+		f.gd += gf * b.ExtraKPa
+		if b.Occupancy != "" {
+			if _, ok := loads.Occupancies[b.Occupancy]; !ok {
+				return Result{}, fmt.Errorf("unknown occupancy %q", b.Occupancy)
+			}
+		}
+		floors[b.Name] = f
+	}
+
+	var res Result
+	for _, el := range in.Elements {
+		if len(el.Areas) > 0 && len(el.Areas) != len(in.Storeys) {
+			return Result{}, fmt.Errorf("areas of %s do not match the storeys", el.Name)
+		}
+		er := ElementResult{Name: el.Name, Kind: el.Kind}
+		g, gd, snow := 0.0, 0.0, 0.0
+		occArea := map[string]float64{}
+		occCount := map[string]int{}
+		var occOrder []string
+		for i, st := range in.Storeys {
+			f, ok := floors[st.BuildUp]
+			if !ok {
+				return Result{}, fmt.Errorf("unknown build-up %q", st.BuildUp)
+			}
+			a := el.AreaM2
+			if len(el.Areas) > 0 {
+				a = el.Areas[i]
+			}
+			if a < 0 {
+				return Result{}, fmt.Errorf("invalid area of %s", el.Name)
+			}
+			g += f.g*a + el.SelfWeightKN
+			gd += f.gd*a + 1.1*el.SelfWeightKN
+			snow += f.snow * a
+			if f.occupancy != "" && a > 0 {
+				if occCount[f.occupancy] == 0 {
+					occOrder = append(occOrder, f.occupancy)
+				}
+				occArea[f.occupancy] += a
+				occCount[f.occupancy]++
+			}
+
+			// Live loads reduced by ψn over the floors carried so far
+			var live []loads.LiveLoad
+			for _, o := range occOrder {
+				live = append(live, loads.LiveLoad{
+					Occupancy: o,
+					AreaM2:    occArea[o] / float64(occCount[o]),
+					Storeys:   occCount[o],
+				})
+			}
+			lvl := Level{Storey: st.Name, LoadGKN: g, SnowKN: snow}
+			cases := []loads.LoadCase{{Name: "G", Type: loads.CaseDead, ValueKN: g, GammaF: gammaOf(gd, g)}}
+			if len(live) > 0 {
+				lc, lr, err := loads.LiveCases(live)
+				if err != nil {
+					return Result{}, err
+				}
+				cases = append(cases, lc...)
+				for _, r := range lr {
+					lvl.LoadQLongKN += r.LongKN
+					lvl.LoadQShortKN += r.FullKN - r.LongKN
+				}
+			}
+			if snow > 0 {
+				cases = append(cases, loads.LoadCase{Name: "Snow", Type: loads.CaseSnow, ValueKN: snow})
+				lvl.LoadQShortKN += snow
+			}
+			lr, err := loads.Calculate(loads.Input{Cases: cases})
+			if err != nil {
+				return Result{}, err
+			}
+			lvl.LoadKN = lr.DesignLoadKN
+			lvl.Formula = lr.Formula
+			if el.LengthM > 0 {
+				lvl.LoadKNm = lvl.LoadKN / el.LengthM
+			}
+			er.Levels = append(er.Levels, lvl)
+		}
+		res.Elements = append(res.Elements, er)
+	}
+	res.Notes = "Vertical load take-down from the roof down; live loads reduced by ψn per occupancy, design forces from SP20 basic combinations."
+	return res, nil
+}
+
+// gammaOf is the mean load factor of the accumulated dead load.
+func gammaOf(design, normative float64) float64 {
+	if normative <= 0 {
+		return 1.1
+	}
+	return math.Round(design/normative*1000) / 1000
+}
@@ -0,0 +1,285 @@
+package wind
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	loads "Vertex/internal/calc/loads"
+)
+
+type Terrain string
+
+const (
+	TerrainA Terrain = "A"
+	TerrainB Terrain = "B"
+	TerrainC Terrain = "C"
+)
+
+// Input describes a rectangular building: WidthM is the facade facing the
+// wind, DepthM the dimension along the wind. DynamicFactor is ξ read from
+// SP20 figure 11.1 and is required when the first natural frequency is
+// given and not shown to be at or above flim.
+type Input struct {
+	Region             string  `json:"region"`
+	W0KPa              float64 `json:"w0_kpa"`
+	Terrain            Terrain `json:"terrain"`
+	HeightM            float64 `json:"height_m"`
+	WidthM             float64 `json:"width_m"`
+	DepthM             float64 `json:"depth_m"`
+	BandM              float64 `json:"band_m"`
+	NaturalFrequencyHz float64 `json:"natural_frequency_hz"`
+	Decrement          float64 `json:"decrement"`
+	DynamicFactor      float64 `json:"dynamic_factor"`
+	GammaF             float64 `json:"gamma_f"`
+	TributaryAreaM2    float64 `json:"tributary_area_m2"`
+}
+
+type Band struct {
+	FromM float64 `json:"from_m"`
+	ToM   float64 `json:"to_m"`
+	ZeM   float64 `json:"ze_m"`
+	K     float64 `json:"k"`
+	Zeta  float64 `json:"zeta"`
+}
+
+type ZonePressure struct {
+	Zone         string  `json:"zone"`
+	Surface      string  `json:"surface"`
+	C            float64 `json:"c"`
+	FromM        float64 `json:"from_m"`
+	ToM          float64 `json:"to_m"`
+	MeanKPa      float64 `json:"mean_kpa"`
+	PulsationKPa float64 `json:"pulsation_kpa"`
+	TotalKPa     float64 `json:"total_kpa"`
+	DesignKPa    float64 `json:"design_kpa"`
+}
+
+type Result struct {
+	W0KPa         float64          `json:"w0_kpa"`
+	Nu            float64          `json:"nu"`
+	FlimHz        float64          `json:"flim_hz,omitempty"`
+	Epsilon       float64          `json:"epsilon,omitempty"`
+	DynamicFactor float64          `json:"dynamic_factor"`
+	Bands         []Band           `json:"bands"`
+	Pressures     []ZonePressure   `json:"pressures"`
+	Cases         []loads.LoadCase `json:"cases"`
+	Notes         string           `json:"notes"`
+}
+
+// Normative wind pressure w0 by region, SP20 table 11.1 (kPa).
+var regionW0 = map[string]float64{
+	"IA": 0.17, "I": 0.23, "II": 0.30, "III": 0.38,
+	"IV": 0.48, "V": 0.60, "VI": 0.73, "VII": 0.85,
+}
+
+var tableHeights = []float64{5, 10, 20, 40, 60, 80, 100, 150, 200, 250, 300, 350, 480}
+
+// k(ze), SP20 table 11.2.
+var kTable = map[Terrain][]float64{
+	TerrainA: {0.75, 1.0, 1.25, 1.5, 1.7, 1.85, 2.0, 2.25, 2.45, 2.65, 2.75, 2.75, 2.75},
+	TerrainB: {0.5, 0.65, 0.85, 1.1, 1.3, 1.45, 1.6, 1.9, 2.1, 2.3, 2.5, 2.75, 2.75},
+	TerrainC: {0.4, 0.4, 0.55, 0.8, 1.0, 1.15, 1.25, 1.55, 1.8, 2.0, 2.25, 2.45, 2.75},
+}
+
+// ζ(ze), SP20 table 11.4.
+var zetaTable = map[Terrain][]float64{
+	TerrainA: {0.85, 0.76, 0.69, 0.62, 0.58, 0.56, 0.54, 0.51, 0.49, 0.47, 0.46, 0.46, 0.46},
+	TerrainB: {1.22, 1.06, 0.92, 0.80, 0.74, 0.70, 0.67, 0.62, 0.58, 0.56, 0.54, 0.52, 0.50},
+	TerrainC: {1.78, 1.78, 1.50, 1.26, 1.14, 1.06, 1.00, 0.90, 0.84, 0.80, 0.76, 0.73, 0.68},
+}
+
+// Spatial correlation ν by ρ (rows) and χ (columns), SP20 table 11.6 (m).
+var (
+	nuRho = []float64{0.1, 5, 10, 20, 40, 80, 160}
+	nuChi = []float64{5, 10, 20, 40, 80, 160, 350}
+	nuTab = [][]float64{
+		{0.95, 0.92, 0.88, 0.83, 0.76, 0.67, 0.56},
+		{0.89, 0.87, 0.84, 0.80, 0.73, 0.65, 0.54},
+		{0.85, 0.84, 0.81, 0.77, 0.71, 0.64, 0.53},
+		{0.80, 0.78, 0.76, 0.73, 0.68, 0.61, 0.51},
+		{0.72, 0.72, 0.70, 0.67, 0.63, 0.57, 0.48},
+		{0.63, 0.63, 0.61, 0.59, 0.56, 0.51, 0.44},
+		{0.53, 0.53, 0.52, 0.50, 0.47, 0.44, 0.38},
+	}
+)
+
+// Limit frequency flim by region for δ = 0.3 and δ = 0.15, SP20 table 11.5 (Hz).
+var flimTable = map[string][2]float64{
+	"IA": {0.85, 2.6}, "I": {0.95, 2.9}, "II": {1.1, 3.4}, "III": {1.2, 3.8},
+	"IV": {1.4, 4.3}, "V": {1.6, 5.0}, "VI": {1.7, 5.6}, "VII": {1.9, 5.9},
+}
+
+func Calculate(in Input) (Result, error) {
+	region := strings.ToUpper(strings.TrimSpace(in.Region))
+	w0 := in.W0KPa
+	if w0 <= 0 {
+		var ok bool
+		if w0, ok = regionW0[region]; !ok {
+			return Result{}, fmt.Errorf("invalid wind region")
+		}
+	}
+	if in.Terrain == "" {
+		in.Terrain = TerrainB
+	}
+	if _, ok := kTable[in.Terrain]; !ok {
+		return Result{}, fmt.Errorf("invalid terrain type")
+	}
+	if in.HeightM <= 0 || in.WidthM <= 0 || in.DepthM <= 0 {
+		return Result{}, fmt.Errorf("invalid building size")
+	}
+	if in.BandM <= 0 {
+		in.BandM = 5
+	}
+	if in.Decrement <= 0 {
+		in.Decrement = 0.3
+	}
+	if in.GammaF <= 0 {
+		in.GammaF = 1.4
+	}
+	if in.TributaryAreaM2 <= 0 {
+		in.TributaryAreaM2 = 1
+	}
+	h, b, d := in.HeightM, in.WidthM, in.DepthM
+
+	res := Result{
+		W0KPa:         w0,
+		Nu:            bilinear(nuRho, nuChi, nuTab, b, h),
+		DynamicFactor: in.DynamicFactor,
+	}
+	if in.NaturalFrequencyHz > 0 {
+		if fl, ok := flimTable[region]; ok {
+			res.FlimHz = fl[0]
+			if in.Decrement < 0.3 {
+				res.FlimHz = fl[1]
+			}
+		}
+		// ε = √(w0·γf·k(ze))/(940·f1) with w0 in Pa, SP20 (11.7)
+		res.Epsilon = math.Sqrt(w0*1000*in.GammaF*interp(tableHeights, kTable[in.Terrain], h)) / (940 * in.NaturalFrequencyHz)
+		switch {
+		case res.FlimHz > 0 && in.NaturalFrequencyHz >= res.FlimHz:
+			res.DynamicFactor = 1
+		case in.DynamicFactor <= 0:
+			// ξ comes from SP20 figure 11.1 by the ε above
+			return Result{}, fmt.Errorf("dynamic factor required for f1 below flim (epsilon %.4f)", res.Epsilon)
+		}
+	} else if res.DynamicFactor <= 0 {
+		res.DynamicFactor = 1
+	}
+
+	for z0 := 0.0; z0 < h-1e-9; z0 += in.BandM {
+		z1 := math.Min(z0+in.BandM, h)
+		ze := equivalentHeight(z1, h, d)
+		res.Bands = append(res.Bands, Band{
+			FromM: z0,
+			ToM:   z1,
+			ZeM:   ze,
+			K:     interp(tableHeights, kTable[in.Terrain], ze),
+			Zeta:  interp(tableHeights, zetaTable[in.Terrain], ze),
+		})
+	}
+
+	// Walls, SP20 appendix B scheme B.2: e = min(b, 2h) along the side walls
+	e := math.Min(b, 2*h)
+	walls := []struct {
+		zone string
+		c    float64
+	}{
+		{"D windward", 0.8},
+		{"E leeward", -0.5},
+		{"A side", -1.0},
+		{"B side", -0.8},
+		{"C side", -0.5},
+	}
+	for _, w := range walls {
+		if w.zone == "C side" && e >= d {
+			continue
+		}
+		if w.zone == "B side" && e/5 >= d {
+			continue
+		}
+		for _, bd := range res.Bands {
+			res.Pressures = append(res.Pressures, pressure(w.zone, "wall", w.c, bd, w0, res.Nu, res.DynamicFactor, in.GammaF))
+		}
+	}
+	// Flat roof with sharp eaves at ze = h
+	top := res.Bands[len(res.Bands)-1]
+	top.FromM, top.ToM = h, h
+	for _, r := range []struct {
+		zone string
+		c    float64
+	}{
+		{"F roof", -1.8},
+		{"G roof", -1.2},
+		{"H roof", -0.7},
+		{"I roof -", -0.2},
+		{"I roof +", 0.2},
+	} {
+		res.Pressures = append(res.Pressures, pressure(r.zone, "roof", r.c, top, w0, res.Nu, res.DynamicFactor, in.GammaF))
+	}
+
+	maxP, minP := 0.0, 0.0
+	for _, p := range res.Pressures {
+		maxP = math.Max(maxP, p.TotalKPa)
+		minP = math.Min(minP, p.TotalKPa)
+	}
+	res.Cases = []loads.LoadCase{
+		{Name: "Wind+", Type: loads.CaseWind, ValueKN: maxP * in.TributaryAreaM2, GammaF: in.GammaF, Group: "wind"},
+		{Name: "Wind-", Type: loads.CaseWind, ValueKN: minP * in.TributaryAreaM2, GammaF: in.GammaF, Group: "wind"},
+	}
+	res.Notes = "Wind load per SP20 section 11: mean w0·k(ze)·c and pulsation wm·ζ·ν·ξ; rectangular building with a flat roof."
+	return res, nil
+}
+
+// equivalentHeight is ze for the point at height z, SP20 11.1.5.
+func equivalentHeight(z, h, d float64) float64 {
+	switch {
+	case h <= d:
+		return h
+	case z >= h-d:
+		return h
+	case h <= 2*d:
+		return h - d
+	case z <= d:
+		return d
+	}
+	return z
+}
+
+func pressure(zone, surface string, c float64, bd Band, w0, nu, xi, gammaF float64) ZonePressure {
+	wm := w0 * bd.K * c
+	wg := wm * bd.Zeta * nu * xi
+	return ZonePressure{
+		Zone:         zone,
+		Surface:      surface,
+		C:            c,
+		FromM:        bd.FromM,
+		ToM:          bd.ToM,
+		MeanKPa:      wm,
+		PulsationKPa: wg,
+		TotalKPa:     wm + wg,
+		DesignKPa:    gammaF * (wm + wg),
+	}
+}
+
+// interp interpolates linearly in a table, clamping at both ends.
+func interp(xs, ys []float64, x float64) float64 {
+	if x <= xs[0] {
+		return ys[0]
+	}
+	for i := 1; i < len(xs); i++ {
+		if x <= xs[i] {
+			t := (x - xs[i-1]) / (xs[i] - xs[i-1])
+			return ys[i-1] + t*(ys[i]-ys[i-1])
+		}
+	}
+	return ys[len(ys)-1]
+}
+
+func bilinear(rows, cols []float64, tab [][]float64, r, c float64) float64 {
+	col := make([]float64, len(rows))
+	for i := range rows {
+		col[i] = interp(cols, tab[i], c)
+	}
+	return interp(rows, col, r)
+}
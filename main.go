@@ -13,13 +13,21 @@ import (
 	slabsp "Vertex/internal/calc/SP/slab-SP"
 	anchors "Vertex/internal/calc/anchors"
 	beam "Vertex/internal/calc/beam"
+	bolts "Vertex/internal/calc/bolts"
 	column "Vertex/internal/calc/column"
 	deflection "Vertex/internal/calc/deflection"
+	endplate "Vertex/internal/calc/endplate"
+	footing "Vertex/internal/calc/footing"
 	joints "Vertex/internal/calc/joints"
 	loads "Vertex/internal/calc/loads"
+	pilegroup "Vertex/internal/calc/pilegroup"
 	piles "Vertex/internal/calc/piles"
 	report "Vertex/internal/calc/report"
+	seismic "Vertex/internal/calc/seismic"
 	slab "Vertex/internal/calc/slab"
+	snow "Vertex/internal/calc/snow"
+	takedown "Vertex/internal/calc/takedown"
+	wind "Vertex/internal/calc/wind"
 	pay "Vertex/internal/pay"
 	pbatch "Vertex/internal/calc/premium/batch"
 	pauto "Vertex/internal/calc/premium/autodesign"
@@ -405,10 +413,15 @@ func HandleList(mux *mux.Router, db *sql.DB) {
 		w.WriteHeader(http.StatusOK)
 	}).Methods("POST")
 
-	pilesH := &piles.Handler{}
+	pilesH := &piles.Handler{WorkbookPath: piles.DefaultWorkbook}
 	secureApi.HandleFunc("/tools/piles/calc", pilesH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/piles/cpt", pilesH.CPT).Methods("POST")
+	secureApi.HandleFunc("/tools/piles/export", pilesH.Export).Methods("POST")
+	secureApi.HandleFunc("/tools/piles/import", pilesH.Import).Methods("POST")
 
 	beamH := &beam.Handler{}
+	boltsH := &bolts.Handler{}
+	endplateH := &endplate.Handler{}
 	anchorsH := &anchors.Handler{}
 	columnH := &column.Handler{}
 	deflectionH := &deflection.Handler{}
@@ -416,21 +429,43 @@ func HandleList(mux *mux.Router, db *sql.DB) {
 	loadsH := &loads.Handler{}
 	reportH := &report.Handler{}
 	slabH := &slab.Handler{}
+	snowH := &snow.Handler{}
+	windH := &wind.Handler{}
+	takedownH := &takedown.Handler{}
+	seismicH := &seismic.Handler{}
+	pilegroupH := &pilegroup.Handler{}
+	footingH := &footing.Handler{}
 	beamSpH := &beamsp.Handler{}
 	anchorsSpH := &anchorssp.Handler{}
 	columnSpH := &columnsp.Handler{}
 	deflectionSpH := &deflectionsp.Handler{}
 	jointsSpH := &jointssp.Handler{}
 	loadsSpH := &loadssp.Handler{}
-	pilesSpH := &pilessp.Handler{}
+	pilesSpH := &pilessp.Handler{WorkbookPath: pilessp.DefaultWorkbook}
 	reportSpH := &reportsp.Handler{}
 	slabSpH := &slabsp.Handler{}
+	if dir := os.Getenv("ANCHOR_CATALOG_DIR"); dir != "" {
+		anchors.CatalogDir = dir
+		anchorssp.CatalogDir = dir
+	}
 
 	secureApi.HandleFunc("/tools/beam/calc", beamH.Calc).Methods("POST")
 	secureApi.HandleFunc("/tools/loads/calc", loadsH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/loads/occupancies", loadsH.Occupancies).Methods("GET")
+	secureApi.HandleFunc("/tools/snow/calc", snowH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/wind/calc", windH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/takedown/calc", takedownH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/seismic/calc", seismicH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/pilegroup/calc", pilegroupH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/footing/calc", footingH.Calc).Methods("POST")
 	secureApi.HandleFunc("/tools/anchors/calc", anchorsH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/anchors/plate", anchorsH.Plate).Methods("POST")
+	secureApi.HandleFunc("/tools/anchors/catalog", anchorsH.Catalog).Methods("GET")
 	secureApi.HandleFunc("/tools/deflection/calc", deflectionH.Calc).Methods("POST")
 	secureApi.HandleFunc("/tools/joints/calc", jointsH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/joints/group", jointsH.Group).Methods("POST")
+	secureApi.HandleFunc("/tools/bolts/calc", boltsH.Calc).Methods("POST")
+	secureApi.HandleFunc("/tools/endplate/calc", endplateH.Calc).Methods("POST")
 	secureApi.HandleFunc("/tools/report/pdf", reportH.Generate).Methods("POST")
 	secureApi.HandleFunc("/tools/column/calc", columnH.Calc).Methods("POST")
 	secureApi.HandleFunc("/tools/slab/calc", slabH.Calc).Methods("POST")
@@ -451,16 +486,25 @@ func HandleList(mux *mux.Router, db *sql.DB) {
 	impH := &pimport.Handler{}
 	premiumTools.HandleFunc("/batch/beam", batchH.Beam).Methods("POST")
 	premiumTools.HandleFunc("/auto/beam", autoH.Beam).Methods("POST")
+	premiumTools.HandleFunc("/auto/piles", autoH.Piles).Methods("POST")
 	premiumTools.HandleFunc("/recommend/weld", recH.Weld).Methods("POST")
+	premiumTools.HandleFunc("/recommend/connection", recH.Connection).Methods("POST")
 	premiumTools.HandleFunc("/import/beam", impH.Beam).Methods("POST")
 
 premiumApi := secureApi.PathPrefix("/tools-sp").Subrouter()
 	premiumApi.Use(premiumMiddleware(userRepo))
 	premiumApi.HandleFunc("/piles/calc", pilesSpH.Calc).Methods("POST")
+	premiumApi.HandleFunc("/piles/cpt", pilesSpH.CPT).Methods("POST")
+	premiumApi.HandleFunc("/piles/export", pilesSpH.Export).Methods("POST")
+	premiumApi.HandleFunc("/piles/import", pilesSpH.Import).Methods("POST")
 	premiumApi.HandleFunc("/beam/calc", beamSpH.Calc).Methods("POST")
 	premiumApi.HandleFunc("/loads/calc", loadsSpH.Calc).Methods("POST")
+	premiumApi.HandleFunc("/loads/occupancies", loadsSpH.Occupancies).Methods("GET")
 	premiumApi.HandleFunc("/anchors/calc", anchorsSpH.Calc).Methods("POST")
+	premiumApi.HandleFunc("/anchors/plate", anchorsSpH.Plate).Methods("POST")
+	premiumApi.HandleFunc("/anchors/catalog", anchorsSpH.Catalog).Methods("GET")
 	premiumApi.HandleFunc("/joints/calc", jointsSpH.Calc).Methods("POST")
+	premiumApi.HandleFunc("/joints/group", jointsSpH.Group).Methods("POST")
 	premiumApi.HandleFunc("/deflection/calc", deflectionSpH.Calc).Methods("POST")
 	premiumApi.HandleFunc("/column/calc", columnSpH.Calc).Methods("POST")
 	premiumApi.HandleFunc("/slab/calc", slabSpH.Calc).Methods("POST")